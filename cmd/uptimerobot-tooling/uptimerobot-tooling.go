@@ -1,31 +1,387 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/internal/pkg/util/fileutil"
 	"github.com/onaio/uptimerobot-tooling/pkg/handler"
 	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/provider"
+	"github.com/onaio/uptimerobot-tooling/pkg/service/monitor"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/planutil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/reportutil"
 	log "github.com/sirupsen/logrus"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 )
 
+// payloadFlag collects every -d flag.
+type payloadFlag []string
+
+func (p *payloadFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *payloadFlag) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// Flags that are passed on to the services as the env variables they override.
+var flagEnvs = map[string]string{
+	"config":                   provider.UptimeRobotConfigEnv,
+	"profile":                  provider.UptimeRobotProfileEnv,
+	"prune":                    monitor.MonitorApplyPruneEnv,
+	"force-prune":              monitor.MonitorApplyForcePruneEnv,
+	"dry-run":                  monitor.MonitorDryRunEnv,
+	"concurrency":              monitor.MonitorConcurrencyEnv,
+	"continue-on-error":        monitor.MonitorContinueOnErrorEnv,
+	"max-attempts":             httputil.UptimeRobotMaxAttemptsEnv,
+	"request-timeout":          httputil.UptimeRobotRequestTimeoutEnv,
+	"fuzzy":                    monitor.MonitorFuzzyMatchEnv,
+	"show-secrets":             monitor.MonitorShowSecretsEnv,
+	"allow-recreate":           monitor.MonitorAllowRecreateEnv,
+	"snapshot-dir":             monitor.MonitorSnapshotDirEnv,
+	"trash-dir":                monitor.MonitorTrashDirEnv,
+	"journal":                  monitor.MonitorJournalEnv,
+	"operator":                 monitor.MonitorOperatorEnv,
+	"redact-fields":            redactutil.UptimeRobotRedactFieldsEnv,
+	"alert-contacts-cache":     monitor.MonitorAlertContactsCacheEnv,
+	"alert-contacts-cache-ttl": monitor.MonitorAlertContactsCacheTTLEnv,
+}
+
 func main() {
-	log.SetFormatter(&log.TextFormatter{
+	os.Exit(run())
+}
+
+/*
+*
+Runs the tool and returns its exit code (see reportutil.ExitCode), invalid flags, env variables and input exit with
+reportutil.ExitInputError and failures outside the handled resources with reportutil.ExitTotalFailure.
+*/
+func run() int {
+	log.SetFormatter(&redactutil.Formatter{Formatter: &log.TextFormatter{
 		FullTimestamp: true,
+	}})
+	// the services only raise fatal errors on invalid env variables and actions, run returns the code of other failures
+	log.StandardLogger().ExitFunc = func(int) { os.Exit(reportutil.ExitInputError) }
+	if len(os.Args) > 1 && os.Args[1] == "journal" {
+		return runJournal(os.Args[2:])
+	}
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+
+	var payloads payloadFlag
+	flag.Var(&payloads, "d", "JSON, YAML or TOML file path, directory, glob, - for stdin or string containing the uptimerobot-tooling robot resource(s). Can be repeated.")
+	resource := flag.String("r", "monitor", "Resource type that will be acted on. e.g monitor, alertcontact, mwindow, psp")
+	action := flag.String("a", "update", "Action to be performed on the model e.g create, update, delete, get, list, apply, export, restore")
+	flag.String("config", "", "YAML config file holding the profiles, defaults to uptimerobot-tooling/config.yaml in the user config directory.")
+	flag.String("profile", "", "Profile of the config file whose env variables are used when neither a flag nor the env sets them, defaults to "+provider.DefaultProfile+".")
+	flag.Bool("prune", false, "On apply, delete remote monitors that are not in the payload.")
+	flag.Bool("force-prune", false, "On apply with -prune, prune even when no monitors are desired i.e. delete every monitor.")
+	flag.Bool("dry-run", false, "Print the changes to monitors as a plan without creating, editing or deleting them.")
+	flag.Int("concurrency", 1, "Number of monitors handled at the same time.")
+	flag.Bool("continue-on-error", true, "Keep handling the remaining monitors after one failed, -continue-on-error=false stops at the first failure. Other resources always stop at the first failure.")
+	flag.Int("max-attempts", httputil.DefaultMaxAttempts, "Number of times a failed or rate limited get/edit request is sent before giving up.")
+	flag.Duration("request-timeout", httputil.DefaultRequestTimeout, "Time a single attempt of an API request may take.")
+	flag.Bool("fuzzy", false, "On update, match a monitor friendly_name ignoring case or partially when no monitor matches exactly.")
+	flag.Bool("show-secrets", false, "On get and list, print the http_username, http_password and credential headers of monitors instead of masking them.")
+	flag.Bool("allow-recreate", false, "Let updates that change the type of a monitor delete and recreate it, losing its uptime history and id.")
+	flag.String("snapshot-dir", "", "Directory monitors are written to before they are recreated, defaults to uptimerobot-tooling/snapshots in the user config directory.")
+	flag.String("trash-dir", "", "Directory deleted and recreated monitors are written to for restore, they are not kept when it is not set.")
+	flag.String("journal", "", "File every monitor create, edit and delete is appended to, writes are not journaled by default.")
+	flag.String("operator", "", "Who the monitor writes are journaled as, defaults to the current user.")
+	flag.String("redact-fields", "", "Comma separated fields whose values are redacted from logs, errors, plans and the journal on top of api_key, http_password and http_username.")
+	flag.String("alert-contacts-cache", "", "File alert contacts resolved by friendly_name are cached in across runs.")
+	flag.Duration("alert-contacts-cache-ttl", monitor.DefaultAlertContactsCacheTTL, "Time alert contacts cached in the -alert-contacts-cache file are used for.")
+	timeout := flag.Duration("timeout", 0, "Time the whole run may take e.g 10m, 0 for no limit.")
+	planFormat := flag.String("plan-format", planutil.TextFormat, "Format of the dry run plan e.g text, markdown")
+	output := flag.String("o", "", "On export, JSON file (or directory with -split) the resources are written to instead of stdout.")
+	split := flag.Bool("split", false, "On export, write one JSON file per resource into the -o directory.")
+	report := flag.String("report", "", "File the per-resource results are written to, as JUnit XML when it ends with .xml otherwise as JSON.")
+	reportFormat := flag.String("report-format", "", "Format of the -report file e.g json, junit. Defaults to the format of its extension.")
+	if err := flag.CommandLine.Parse(os.Args[1:]); err == flag.ErrHelp {
+		return reportutil.ExitSuccess
+	} else if err != nil {
+		return reportutil.ExitInputError
+	}
+	if *report != "" && *reportFormat == "" {
+		*reportFormat = reportutil.FormatOf(*report)
+	}
+	if *report != "" {
+		if err := reportutil.ValidateFormat(*reportFormat); err != nil {
+			log.Error(err)
+			return reportutil.ExitInputError
+		}
+	}
+	if err := planutil.ValidateFormat(*planFormat); err != nil {
+		log.Error(err)
+		return reportutil.ExitInputError
+	}
+
+	if *split && *output == "" {
+		log.Error("-split requires an -o directory")
+		return reportutil.ExitInputError
+	}
+
+	continueOnErrorSet := false
+	var setenvErr error
+	flag.Visit(func(f *flag.Flag) {
+		continueOnErrorSet = continueOnErrorSet || f.Name == "continue-on-error"
+		if env, exists := flagEnvs[f.Name]; exists && setenvErr == nil {
+			setenvErr = os.Setenv(env, f.Value.String())
+		}
 	})
+	if setenvErr != nil {
+		log.Error(setenvErr)
+		return reportutil.ExitTotalFailure
+	}
 
-	payload := flag.String("d", "", "JSON file path or JSON string containing the uptimerobot-tooling robot resource(s).")
-	resource := flag.String("r", "monitor", "Resource type that will be acted on. e.g monitor, alert_contact")
-	action := flag.String("a", "update", "Action to be performed on the model e.g create, update, delete")
-	flag.Parse()
-
-	if resultPayload := handler.HandleRequest(*payload, *resource, *action); resultPayload != nil {
-		for _, value := range resultPayload {
-			if value != nil {
-				if value[model.ErrorResultField] == nil {
-					log.Infof("%s action on monitor %s was successful", *action, value[model.MonitorNameResultField])
-				} else {
-					log.Errorf("%s action on monitor %s failed with '%v'", *action, value[model.MonitorNameResultField], value[model.ErrorResultField])
-				}
+	if err := loadConfig("", ""); err != nil {
+		log.Error(err)
+		return reportutil.ExitInputError
+	}
+
+	dryRun := false
+	if val, found := provider.LookUpEnv(monitor.MonitorDryRunEnv); found {
+		var err error
+		if dryRun, err = strconv.ParseBool(val); err != nil {
+			log.Errorf("invalid %s: %v", monitor.MonitorDryRunEnv, err)
+			return reportutil.ExitInputError
+		}
+	}
+	if dryRun && !strings.EqualFold(*resource, model.Monitor) {
+		log.Errorf("dry run is only supported for the %s resource", model.Monitor)
+		return reportutil.ExitInputError
+	}
+	if continueOnErrorSet && !strings.EqualFold(*resource, model.Monitor) {
+		// the other resources always stop at the first failure, the rest are counted as skipped
+		log.Errorf("-continue-on-error is only supported for the %s resource", model.Monitor)
+		return reportutil.ExitInputError
+	}
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-sigCtx.Done()
+		// a second signal terminates right away
+		stop()
+	}()
+	ctx := sigCtx
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(sigCtx, *timeout)
+		defer cancel()
+	}
+
+	resultPayload := handler.HandleRequests(ctx, payloads, *resource, *action)
+	if resultPayload == nil {
+		// the input could not be read or the resource is not supported
+		return reportutil.ExitInputError
+	}
+	nameField := model.NameResultFields[strings.ToLower(*resource)]
+	for _, value := range resultPayload {
+		if value != nil {
+			entry := log.NewEntry(log.StandardLogger())
+			if value[model.SourceResultField] != nil {
+				entry = entry.WithField(model.SourceResultField, value[model.SourceResultField])
 			}
+			if value[model.ErrorResultField] == nil && value[model.ActionResultField] != nil {
+				entry.Infof("%s action on %s %s was successful (%v)", *action, *resource, value[nameField], value[model.ActionResultField])
+			} else if value[model.ErrorResultField] == nil {
+				entry.Infof("%s action on %s %s was successful", *action, *resource, value[nameField])
+			} else {
+				entry.Errorf("%s action on %s %s failed with '%v'", *action, *resource, value[nameField], value[model.ErrorResultField])
+			}
+		}
+	}
+
+	drift := false
+	if model.Args(*action) == model.Get || model.Args(*action) == model.List || (model.Args(*action) == model.Export && *output == "") {
+		printData(resultPayload)
+	} else if model.Args(*action) == model.Export {
+		if err := fileutil.WriteJsonFiles(collectData(resultPayload), *output, httputil.FriendlyNameField, *split); err != nil {
+			// the exported resources were fetched but none of them reached the output
+			log.Error(err)
+			return reportutil.ExitTotalFailure
+		}
+	} else if dryRun {
+		drift = printPlan(resultPayload, *resource, *planFormat)
+	}
+
+	if ctx.Err() != nil {
+		log.Warnf("run stopped early (%v), the %s(s) that were not handled are counted as skipped", ctx.Err(), *resource)
+	}
+	// stderr keeps stdout to the data and plan
+	summary := reportutil.Summarise(resultPayload, nameField)
+	fmt.Fprint(os.Stderr, summary.Render(strings.ToLower(*resource)))
+
+	exitCode := reportutil.ExitCode(summary, drift)
+	if *report != "" {
+		if err := reportutil.NewReport(*resource, *action, resultPayload, exitCode).Write(*report, *reportFormat); err != nil {
+			log.Error(err)
+			return reportutil.ExitInputError
 		}
 	}
+	return exitCode
+}
+
+/*
+*
+Runs the journal subcommand, which prints the journal entries selected by args as JSON lines on stdout e.g.
+uptimerobot-tooling journal -name api -since 24h.
+*/
+func runJournal(args []string) int {
+	flags := flag.NewFlagSet(os.Args[0]+" journal", flag.ContinueOnError)
+	path := flags.String("journal", "", "Journal file to read, defaults to MONITOR_JOURNAL.")
+	name := flags.String("name", "", "Only print the entries of the monitor with this friendly_name.")
+	since := flags.String("since", "", "Only print the entries written at or after this RFC 3339 time or this long ago e.g. 2024-01-02T15:04:05Z, 24h.")
+	until := flags.String("until", "", "Only print the entries written at or before this RFC 3339 time or this long ago.")
+	config := flags.String("config", "", "YAML config file holding the profiles, defaults to uptimerobot-tooling/config.yaml in the user config directory.")
+	profile := flags.String("profile", "", "Profile of the config file whose MONITOR_JOURNAL is read, defaults to "+provider.DefaultProfile+".")
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return reportutil.ExitSuccess
+	} else if err != nil {
+		return reportutil.ExitInputError
+	}
+
+	if err := loadConfig(*config, *profile); err != nil {
+		log.Error(err)
+		return reportutil.ExitInputError
+	}
+
+	now := time.Now()
+	query := monitor.JournalQuery{Name: *name}
+	var err error
+	if query.Since, err = parseJournalTime(*since, now); err != nil {
+		log.Errorf("invalid -since: %v", err)
+		return reportutil.ExitInputError
+	}
+	if query.Until, err = parseJournalTime(*until, now); err != nil {
+		log.Errorf("invalid -until: %v", err)
+		return reportutil.ExitInputError
+	}
+
+	monitorService := monitor.New()
+	if *path != "" {
+		monitorService.Journal = *path
+	}
+	entries, err := monitorService.JournalRequest(query)
+	if err != nil {
+		log.Error(err)
+		return reportutil.ExitInputError
+	}
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Error(err)
+			return reportutil.ExitTotalFailure
+		}
+		fmt.Println(string(line))
+	}
+	return reportutil.ExitSuccess
+}
+
+/*
+*
+Loads the profile of the config file (see provider.LoadConfig) that the services fall back to for the env variables
+neither a flag nor the env sets. Warns about the variables of a selected profile that a flag or the env overrides.
+*/
+func loadConfig(path string, profile string) error {
+	configProvider, err := provider.LoadConfig(path, profile)
+	if err != nil {
+		return err
+	}
+	if configProvider == nil {
+		return nil
+	}
+	log.Infof("using profile %s of %s", configProvider.Profile, configProvider.Path)
+	if configProvider.Explicit {
+		// an exported variable e.g. the API key of another account silently winning over the selected profile is a mix-up
+		overrides := configProvider.Overrides()
+		variables := make([]string, 0, len(overrides))
+		for variable := range overrides {
+			variables = append(variables, variable)
+		}
+		sort.Strings(variables)
+		for _, variable := range variables {
+			log.Warnf(provider.MsgProfileOverridden, overrides[variable], variable, configProvider.Profile, configProvider.Path)
+		}
+	}
+	return nil
+}
+
+// Returns value, an RFC 3339 time or a duration before now, as a time. An empty value is the zero time.
+func parseJournalTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	ago, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is neither an RFC 3339 time nor a duration", value)
+	}
+	return now.Add(-ago), nil
+}
+
+/*
+*
+Prints the resources returned by get/list as a JSON array on stdout so that it can be used as input.
+*/
+func printData(resultPayload []map[string]interface{}) {
+	output, err := json.MarshalIndent(collectData(resultPayload), "", "  ")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	fmt.Println(string(output))
+}
+
+// Returns the resources returned by get/list/export on all results.
+func collectData(resultPayload []map[string]interface{}) []map[string]interface{} {
+	data := make([]map[string]interface{}, 0)
+	for _, value := range resultPayload {
+		if items, ok := value[model.DataResultField].([]map[string]interface{}); ok {
+			data = append(data, items...)
+		}
+	}
+	return data
+}
+
+/*
+*
+Prints the changes recorded on a dry run on stdout in the requested format. Returns whether any monitor would change.
+*/
+func printPlan(resultPayload []map[string]interface{}, resource string, format string) bool {
+	changes := make([]model.Change, 0)
+	for _, value := range resultPayload {
+		if plan, ok := value[model.PlanResultField].([]model.Change); ok {
+			changes = append(changes, plan...)
+		}
+	}
+
+	drift := false
+	for _, change := range changes {
+		if change.Action != model.Unchanged {
+			drift = true
+		}
+	}
+
+	output, err := planutil.Render(strings.ToLower(resource), changes, format)
+	if err != nil {
+		log.Error(err)
+		return drift
+	}
+	fmt.Print(output)
+	return drift
 }
@@ -2,14 +2,39 @@ package fileutil
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
 	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 )
 
+const (
+	JsonFormat = "json"
+	YamlFormat = "yaml"
+	TomlFormat = "toml"
+)
+
+// Formats of input files by extension.
+var fileFormats = map[string]string{
+	".json": JsonFormat,
+	".yaml": YamlFormat,
+	".yml":  YamlFormat,
+	".toml": TomlFormat,
+}
+
+var (
+	tomlTableRegex    = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_."-]+\s*\]\]?\s*(#.*)?$`)
+	tomlKeyValueRegex = regexp.MustCompile(`^[A-Za-z0-9_."-]+\s*=`)
+)
+
 func isFile(payload string) bool {
-	return strings.HasSuffix(strings.TrimSpace(payload), ".json")
+	_, exists := fileFormats[strings.ToLower(filepath.Ext(strings.TrimSpace(payload)))]
+	return exists
 }
 
 func isStringJsonArray(payload string) bool {
@@ -24,29 +49,73 @@ func convertFileToString(filename string) (string, error) {
 	return string(data), err
 }
 
-func TransformInputToString(data string) (string, error) {
-	data = strings.TrimSpace(data)
-	if data != "" {
-		if isFile(data) {
-			str, err := convertFileToString(data)
-			if err != nil {
-				return "", err
-			} else {
-				data = str
-			}
+/*
+*
+Guesses the format of data from its first line that is not blank or a comment: TOML tables and key = value pairs are
+TOML, otherwise data starting with [ or { is JSON and anything else YAML.
+*/
+func sniffFormat(data string) string {
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
+		if tomlTableRegex.MatchString(line) || tomlKeyValueRegex.MatchString(line) {
+			return TomlFormat
+		}
+		if strings.HasPrefix(line, "[") || strings.HasPrefix(line, "{") {
+			return JsonFormat
+		}
+		return YamlFormat
+	}
+	return JsonFormat
+}
 
+/*
+*
+Reads data and returns it as a JSON array string. data can be the content itself, a file path (.json, .yaml, .yml or
+.toml), a directory, a glob pattern or - for stdin (see readInput), the resources of several files are merged. The format
+of a file is chosen by its extension otherwise it is sniffed (see sniffFormat).
+*/
+func TransformInputToString(data string) (string, error) {
+	documents, err := readInput(data)
+	if err != nil {
+		return "", err
+	}
+
+	if len(documents) == 1 && documents[0].format == JsonFormat {
+		data = documents[0].data
 		if !isStringJsonArray(data) {
 			data = convertStringToJsonArray(data)
 		}
-
 		return data, nil
-	} else {
-		return "", errors.New("input is blank")
 	}
+
+	dataMaps := make([]map[string]interface{}, 0)
+	for _, document := range documents {
+		documentMaps, err := document.parse()
+		if err != nil {
+			return "", err
+		}
+		dataMaps = append(dataMaps, documentMaps...)
+	}
+	output, err := json.Marshal(dataMaps)
+	return string(output), err
 }
 
+/*
+*
+Parses data as JSON, YAML or TOML (see sniffFormat) into one map per resource.
+*/
 func TransformStringToMapInterface(data string) ([]map[string]interface{}, error) {
+	if format := sniffFormat(data); format != JsonFormat {
+		dataMaps, err := parseDocuments(data, format)
+		if err != nil {
+			log.Error(err)
+		}
+		return dataMaps, err
+	}
+
 	var dataMaps = make([]map[string]interface{}, 0)
 
 	err := json.Unmarshal([]byte(data), &dataMaps)
@@ -58,3 +127,103 @@ func TransformStringToMapInterface(data string) ([]map[string]interface{}, error
 
 	return dataMaps, nil
 }
+
+/*
+*
+Parses YAML or TOML data into one map per resource, values are converted the way they would be if the data was JSON.
+Every YAML document can be a single resource or a list of them. A TOML document is a single resource unless its only
+key is an array of tables e.g. [[monitors]], in which case each table is a resource.
+*/
+func parseDocuments(data string, format string) ([]map[string]interface{}, error) {
+	var documents []interface{}
+	if format == TomlFormat {
+		document := make(map[string]interface{})
+		if _, err := toml.Decode(data, &document); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+		if len(document) == 1 {
+			for _, value := range document {
+				if tables, ok := value.([]map[string]interface{}); ok {
+					documents = []interface{}{tables}
+				}
+			}
+		}
+	} else {
+		decoder := yaml.NewDecoder(strings.NewReader(data))
+		for {
+			var document interface{}
+			if err := decoder.Decode(&document); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			if document != nil {
+				documents = append(documents, document)
+			}
+		}
+	}
+
+	var resources []interface{}
+	for _, document := range documents {
+		if documentArr, ok := document.([]interface{}); ok {
+			resources = append(resources, documentArr...)
+		} else if tables, ok := document.([]map[string]interface{}); ok {
+			for _, table := range tables {
+				resources = append(resources, table)
+			}
+		} else {
+			resources = append(resources, document)
+		}
+	}
+
+	output, err := json.Marshal(resources)
+	if err != nil {
+		return nil, err
+	}
+	dataMaps := make([]map[string]interface{}, 0)
+	if err := json.Unmarshal(output, &dataMaps); err != nil {
+		return nil, fmt.Errorf("%s input needs to be a resource or a list of resources: %v", format, err)
+	}
+	return dataMaps, nil
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+/*
+*
+Writes items as a JSON array to path, or when split is true as one JSON object per item to path which is created as a
+directory. Files are named after nameField of the item and are readable back by TransformInputToString.
+*/
+func WriteJsonFiles(items []map[string]interface{}, path string, nameField string, split bool) error {
+	if !split {
+		return writeJsonFile(path, items)
+	}
+
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return err
+	}
+	used := make(map[string]int)
+	for idx, item := range items {
+		name := strings.Trim(unsafeFileNameChars.ReplaceAllString(fmt.Sprint(item[nameField]), "_"), "_.")
+		if item[nameField] == nil || name == "" {
+			name = fmt.Sprintf("item-%d", idx+1)
+		}
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, used[name])
+		}
+		if err := writeJsonFile(filepath.Join(path, name+".json"), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJsonFile(filename string, data interface{}) error {
+	output, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(output, '\n'), 0o644)
+}
@@ -1,6 +1,12 @@
 package fileutil
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
 
 func TestProcessInput(t *testing.T) {
 	type args struct {
@@ -16,6 +22,10 @@ func TestProcessInput(t *testing.T) {
 		{name: "should return an error File Not Found", args: args{data: "../testdata/testJsonObject2-1234.json"}, want: "", wantErr: true},
 		{name: "should return empty Json Array when given empty input", args: args{data: ""}, want: "", wantErr: true},
 		{name: "should return an invalid Json Object when given an invalid input", args: args{data: "{\"friendly_name\":\"test\""}, want: "[{\"friendly_name\":\"test\"]", wantErr: false},
+		{name: "should convert a multi-document yaml file to a Json Array", args: args{data: "../testdata/test.yaml"}, want: "[{\"friendly_name\":\"test\",\"interval\":300,\"url\":\"https://example.com\"},{\"friendly_name\":\"test-2\",\"type\":\"HTTP\"}]", wantErr: false},
+		{name: "should convert a toml file with an array of tables to a Json Array", args: args{data: "../testdata/test.toml"}, want: "[{\"friendly_name\":\"test\",\"interval\":300},{\"friendly_name\":\"test-2\"}]", wantErr: false},
+		{name: "should convert inline yaml to a Json Array", args: args{data: "friendly_name: test"}, want: "[{\"friendly_name\":\"test\"}]", wantErr: false},
+		{name: "should return an error when yaml is not a resource", args: args{data: "- - test"}, want: "", wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -94,6 +104,8 @@ func Test_isFile(t *testing.T) {
 		{name: "should return false when given empty string", args: args{payload: ""}, want: false},
 		{name: "should return false non file string", args: args{payload: "{}"}, want: false},
 		{name: "should return true when valid file is supplied", args: args{payload: "testJsonArray.json"}, want: true},
+		{name: "should return true when a yaml file is supplied", args: args{payload: "monitors.YML"}, want: true},
+		{name: "should return true when a toml file is supplied", args: args{payload: "monitors.toml"}, want: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -125,3 +137,112 @@ func Test_isStringJsonArray(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteJsonFiles(t *testing.T) {
+	items := []map[string]interface{}{
+		{"friendly_name": "api/staging", "interval": float64(60)},
+		{"friendly_name": "api/staging"},
+		{"url": "https://example.com"},
+	}
+	tests := []struct {
+		name      string
+		split     bool
+		wantFiles []string
+	}{
+		{name: "should write one combined array", split: false},
+		{name: "should write one file per item named by friendly_name", split: true, wantFiles: []string{"api_staging-2.json", "api_staging.json", "item-3.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "export.json")
+			if tt.split {
+				path = filepath.Join(t.TempDir(), "export")
+			}
+			if err := WriteJsonFiles(items, path, "friendly_name", tt.split); err != nil {
+				t.Fatalf("WriteJsonFiles() error = %v", err)
+			}
+
+			if !tt.split {
+				input, err := TransformInputToString(path)
+				if err != nil {
+					t.Fatalf("TransformInputToString() error = %v", err)
+				}
+				got, err := TransformStringToMapInterface(input)
+				if err != nil || !reflect.DeepEqual(got, items) {
+					t.Errorf("WriteJsonFiles() wrote %v, want %v", got, items)
+				}
+				return
+			}
+
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var files []string
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+			}
+			sort.Strings(files)
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("WriteJsonFiles() wrote %v, want %v", files, tt.wantFiles)
+			}
+			input, err := TransformInputToString(filepath.Join(path, "api_staging.json"))
+			if err != nil || input[0] != '[' {
+				t.Errorf("TransformInputToString() = %v, %v", input, err)
+			}
+		})
+	}
+}
+
+func Test_sniffFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "should detect a Json Object", data: "{\"friendly_name\":\"test\"}", want: JsonFormat},
+		{name: "should detect a Json Array", data: "[\n  {\"friendly_name\":\"test\"}\n]", want: JsonFormat},
+		{name: "should detect yaml after comments", data: "# monitors\n\nfriendly_name: test", want: YamlFormat},
+		{name: "should detect a yaml stream", data: "---\nfriendly_name: test", want: YamlFormat},
+		{name: "should detect a toml key value pair", data: "friendly_name = \"test\"", want: TomlFormat},
+		{name: "should detect a toml array of tables", data: "[[monitors]]\nfriendly_name = \"test\"", want: TomlFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sniffFormat(tt.data); got != tt.want {
+				t.Errorf("sniffFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformStringToMapInterface(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []map[string]interface{}
+		wantErr bool
+	}{
+		{name: "should parse a Json Array", data: "[{\"friendly_name\":\"test\",\"interval\":300}]", want: []map[string]interface{}{{"friendly_name": "test", "interval": float64(300)}}},
+		{name: "should parse yaml the same way as json", data: "friendly_name: test\ninterval: 300\n---\nfriendly_name: test-2", want: []map[string]interface{}{
+			{"friendly_name": "test", "interval": float64(300)},
+			{"friendly_name": "test-2"},
+		}},
+		{name: "should parse a toml table as a single resource", data: "friendly_name = \"test\"\n[http]\nport = 80", want: []map[string]interface{}{
+			{"friendly_name": "test", "http": map[string]interface{}{"port": float64(80)}},
+		}},
+		{name: "should return an error on invalid toml", data: "friendly_name = ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TransformStringToMapInterface(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TransformStringToMapInterface() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TransformStringToMapInterface() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package fileutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// StdinInput is the input that is read from stdin.
+	StdinInput = "-"
+	// StdinSource is the name of stdin in sources.
+	StdinSource = "stdin"
+)
+
+// Stdin is read when the input is StdinInput.
+var Stdin io.Reader = os.Stdin
+
+// Source is where a resource was read from i.e. the file (empty for inline input) and its index in that file.
+type Source struct {
+	File  string
+	Index int
+}
+
+func (source Source) String() string {
+	if source.File == "" {
+		return fmt.Sprintf("input[%d]", source.Index)
+	}
+	return fmt.Sprintf("%s[%d]", source.File, source.Index)
+}
+
+// inputDocument is the content of a file, stdin or inline input.
+type inputDocument struct {
+	source string
+	data   string
+	format string
+}
+
+/*
+*
+Reads data which is either - for stdin, a directory (all supported files in it recursively), a glob pattern, a file or
+the content itself.
+*/
+func readInput(data string) ([]inputDocument, error) {
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return nil, fmt.Errorf("input is blank")
+	}
+
+	if data == StdinInput {
+		content, err := io.ReadAll(Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return []inputDocument{{source: StdinSource, data: strings.TrimSpace(string(content)), format: sniffFormat(string(content))}}, nil
+	}
+
+	if info, err := os.Stat(data); err == nil && info.IsDir() {
+		files, err := findInputFiles(data)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no .json, .yaml, .yml or .toml files found in %s", data)
+		}
+		return readInputFiles(files)
+	}
+
+	if isGlob(data) {
+		matches, err := filepath.Glob(data)
+		if err == nil && len(matches) > 0 {
+			var files []string
+			for _, match := range matches {
+				if info, err := os.Stat(match); err == nil && info.IsDir() {
+					dirFiles, err := findInputFiles(match)
+					if err != nil {
+						return nil, err
+					}
+					files = append(files, dirFiles...)
+				} else if isFile(match) {
+					files = append(files, match)
+				}
+			}
+			if len(files) == 0 {
+				return nil, fmt.Errorf("no .json, .yaml, .yml or .toml files match %s", data)
+			}
+			return readInputFiles(files)
+		} else if isFile(data) {
+			return nil, fmt.Errorf("no files match %s", data)
+		}
+	}
+
+	if isFile(data) {
+		return readInputFiles([]string{data})
+	}
+	return []inputDocument{{data: data, format: sniffFormat(data)}}, nil
+}
+
+// Glob patterns are single line paths, which rules out JSON objects and multi-line YAML/TOML.
+func isGlob(data string) bool {
+	return strings.ContainsAny(data, "*?[") && !strings.ContainsAny(data, "\n{")
+}
+
+// Returns the supported files under dir sorted by path.
+func findInputFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && isFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+func readInputFiles(files []string) ([]inputDocument, error) {
+	documents := make([]inputDocument, 0, len(files))
+	for _, file := range files {
+		content, err := convertFileToString(file)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, inputDocument{
+			source: file,
+			data:   strings.TrimSpace(content),
+			format: fileFormats[strings.ToLower(filepath.Ext(file))],
+		})
+	}
+	return documents, nil
+}
+
+func (document inputDocument) parse() ([]map[string]interface{}, error) {
+	if document.format != JsonFormat {
+		return parseDocuments(document.data, document.format)
+	}
+
+	data := document.data
+	if !isStringJsonArray(data) {
+		data = convertStringToJsonArray(data)
+	}
+	dataMaps := make([]map[string]interface{}, 0)
+	if err := json.Unmarshal([]byte(data), &dataMaps); err != nil {
+		return nil, err
+	}
+	return dataMaps, nil
+}
+
+/*
+*
+Reads every input (see readInput) and merges the resources into one batch, returning the source of each resource at the
+same index. Errors name the file they occurred in.
+*/
+func ReadInputs(inputs []string) ([]map[string]interface{}, []Source, error) {
+	dataMaps := make([]map[string]interface{}, 0)
+	sources := make([]Source, 0)
+	for _, input := range inputs {
+		documents, err := readInput(input)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, document := range documents {
+			documentMaps, err := document.parse()
+			if err != nil {
+				if document.source != "" {
+					return nil, nil, fmt.Errorf("%s: %v", document.source, err)
+				}
+				return nil, nil, err
+			}
+			for index, dataMap := range documentMaps {
+				dataMaps = append(dataMaps, dataMap)
+				sources = append(sources, Source{File: document.source, Index: index})
+			}
+		}
+	}
+	return dataMaps, sources, nil
+}
@@ -0,0 +1,83 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadInputs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"monitors/api.json":     `[{"friendly_name":"api"},{"friendly_name":"api-staging"}]`,
+		"monitors/web/web.yaml": "friendly_name: web\n",
+		"monitors/notes.txt":    "not an input",
+		"psps.toml":             `friendly_name = "status"`,
+		"invalid.json":          `{"friendly_name":`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	apiFile := filepath.Join(dir, "monitors", "api.json")
+	webFile := filepath.Join(dir, "monitors", "web", "web.yaml")
+	pspFile := filepath.Join(dir, "psps.toml")
+
+	tests := []struct {
+		name        string
+		inputs      []string
+		stdin       string
+		want        []map[string]interface{}
+		wantSources []Source
+		wantErr     string
+	}{
+		{name: "should read supported files in a directory recursively", inputs: []string{filepath.Join(dir, "monitors")}, want: []map[string]interface{}{
+			{"friendly_name": "api"}, {"friendly_name": "api-staging"}, {"friendly_name": "web"},
+		}, wantSources: []Source{{File: apiFile, Index: 0}, {File: apiFile, Index: 1}, {File: webFile, Index: 0}}},
+		{name: "should merge repeated inputs, globs, stdin and inline input", inputs: []string{filepath.Join(dir, "*.toml"), "-", `{"friendly_name":"inline"}`}, stdin: "friendly_name: piped", want: []map[string]interface{}{
+			{"friendly_name": "status"}, {"friendly_name": "piped"}, {"friendly_name": "inline"},
+		}, wantSources: []Source{{File: pspFile, Index: 0}, {File: StdinSource, Index: 0}, {Index: 0}}},
+		{name: "should name the file an error occurred in", inputs: []string{filepath.Join(dir, "*.json")}, wantErr: filepath.Join(dir, "invalid.json")},
+		{name: "should fail when a glob matches no files", inputs: []string{filepath.Join(dir, "*.yml")}, wantErr: "no files match"},
+		{name: "should fail when a directory has no supported files", inputs: []string{t.TempDir()}, wantErr: "no .json, .yaml, .yml or .toml files found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Stdin = strings.NewReader(tt.stdin)
+			defer func() { Stdin = os.Stdin }()
+
+			got, sources, err := ReadInputs(tt.inputs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ReadInputs() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadInputs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadInputs() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(sources, tt.wantSources) {
+				t.Errorf("ReadInputs() sources = %v, want %v", sources, tt.wantSources)
+			}
+		})
+	}
+}
+
+func TestSource_String(t *testing.T) {
+	if got := (Source{File: "monitors.yaml", Index: 2}).String(); got != "monitors.yaml[2]" {
+		t.Errorf("String() = %v", got)
+	}
+	if got := (Source{Index: 1}).String(); got != "input[1]" {
+		t.Errorf("String() = %v", got)
+	}
+}
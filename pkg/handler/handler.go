@@ -1,33 +1,80 @@
 package handler
 
 import (
+	"context"
 	"github.com/onaio/uptimerobot-tooling/internal/pkg/util/fileutil"
 	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/service/alertcontact"
 	"github.com/onaio/uptimerobot-tooling/pkg/service/monitor"
+	"github.com/onaio/uptimerobot-tooling/pkg/service/mwindow"
+	"github.com/onaio/uptimerobot-tooling/pkg/service/psp"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
 	log "github.com/sirupsen/logrus"
 	"strings"
 )
 
-func HandleRequest(payload string, resource string, action string) []map[string]interface{} {
-	input, err := fileutil.TransformInputToString(payload)
+func HandleRequest(ctx context.Context, payload string, resource string, action string) []map[string]interface{} {
+	return HandleRequests(ctx, []string{payload}, resource, action)
+}
+
+/*
+*
+Merges the resources of every payload (see fileutil.ReadInputs) into one batch and handles it, nil is returned when the
+input cannot be read or action is not supported on resource (see model.ValidateAction). Results of resources read
+from a file or stdin carry their source so that errors can be traced back, monitor writes are journaled with it too.
+Once ctx is done the requests in flight fail and no further resources are handled, their results are left nil.
+*/
+func HandleRequests(ctx context.Context, payloads []string, resource string, action string) []map[string]interface{} {
+	if err := model.ValidateAction(resource, model.Args(action)); err != nil {
+		log.Error(err)
+		return nil
+	}
+	if strings.TrimSpace(strings.Join(payloads, "")) == "" && (model.Args(action) == model.List || model.Args(action) == model.Export) {
+		// listing without filters and exporting return everything
+		payloads = []string{"{}"}
+	}
+	mapInterface, sources, err := fileutil.ReadInputs(payloads)
+	if err != nil {
+		log.Error(err)
+		return nil
+	}
+	redactutil.Collect(mapInterface)
+
 	var resultPayload []map[string]interface{} = nil
-	if err == nil {
-		if mapInterface, err := fileutil.TransformStringToMapInterface(input); err == nil {
-			if strings.EqualFold(resource, model.Monitor) {
+	if strings.EqualFold(resource, model.Monitor) {
 
-				monitorService := monitor.New()
-				resultPayload = monitorService.HandleRequest(mapInterface, model.Args(action))
-			} else if strings.EqualFold(resource, model.AlertContact) {
-				//TODO add alert-contact service
-				log.Error("alert contact not supported yet")
-			} else {
-				log.Errorf("invalid resource %s specified", resource)
+		monitorService := monitor.New()
+		monitorService.Sources = make([]string, len(sources))
+		for idx, source := range sources {
+			if source.File != "" {
+				monitorService.Sources[idx] = source.String()
 			}
-		} else {
-			log.Error(err)
 		}
+		resultPayload = monitorService.HandleRequest(ctx, mapInterface, model.Args(action))
+	} else if strings.EqualFold(resource, model.AlertContact) {
+		alertContactService := alertcontact.New()
+		resultPayload = alertContactService.HandleRequest(ctx, mapInterface, model.Args(action))
+	} else if strings.EqualFold(resource, model.MWindow) {
+		mWindowService := mwindow.New()
+		resultPayload = mWindowService.HandleRequest(ctx, mapInterface, model.Args(action))
+	} else if strings.EqualFold(resource, model.PSP) {
+		pspService := psp.New()
+		resultPayload = pspService.HandleRequest(ctx, mapInterface, model.Args(action))
 	} else {
-		log.Error(err)
+		log.Errorf("invalid resource %s specified", resource)
+	}
+
+	for _, result := range resultPayload {
+		if err, ok := result[model.ErrorResultField].(error); ok {
+			result[model.ErrorResultField] = redactutil.Error(err)
+		}
+	}
+	if model.Args(action) != model.Export {
+		for idx, source := range sources {
+			if idx < len(resultPayload) && len(resultPayload[idx]) > 0 && source.File != "" {
+				resultPayload[idx][model.SourceResultField] = source.String()
+			}
+		}
 	}
 	return resultPayload
 }
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/service/alertcontact"
 	"github.com/onaio/uptimerobot-tooling/pkg/service/monitor"
 	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
 	"reflect"
@@ -22,7 +24,12 @@ func TestHandleRequest(t *testing.T) {
 	}{
 		{name: "Should Return Nil When Given Empty Payload", args: args{payload: "", action: model.Create, resource: model.Monitor}, want: nil},
 		{name: "Should Return Nil When Given File Not Found", args: args{payload: "tester-123.json", action: model.Create, resource: model.Monitor}, want: nil},
-		{name: "Should Return Nil When Action Is Not Supported", args: args{payload: "{\"friendly_name\":\"test\"}", action: model.Create, resource: model.AlertContact}, want: nil},
+		{name: "Should Return Nil When Resource Is Not Supported", args: args{payload: "{\"friendly_name\":\"test\"}", action: model.Create, resource: "status"}, want: nil},
+		{name: "Should Return Nil When Action Is Not Supported On Resource", args: args{payload: "", action: model.Export, resource: model.PSP}, want: nil},
+		{name: "Should Return Alert Contact Result Payload When Given Alert Contact Resource", args: args{payload: "{\"friendly_name\":\"test\"}", action: model.Create, resource: model.AlertContact}, want: []map[string]interface{}{{
+			model.ErrorResultField:            fmt.Errorf(alertcontact.MsgFieldMissing, httputil.TypeField),
+			model.AlertContactNameResultField: "test",
+		}}},
 		{name: "Should Return Result Payload When Given Valid JSON Payload", args: args{payload: "{\"friendly_name\":\"test\"}", action: "create", resource: "monitor"}, want: []map[string]interface{}{{
 			model.ErrorResultField:       fmt.Errorf(monitor.MsgFieldMissing, httputil.TypeField),
 			model.MonitorNameResultField: "test",
@@ -30,9 +37,42 @@ func TestHandleRequest(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := HandleRequest(tt.args.payload, tt.args.resource, tt.args.action); !reflect.DeepEqual(got, tt.want) {
+			if got := HandleRequest(context.Background(), tt.args.payload, tt.args.resource, tt.args.action); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("HandleRequest() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestHandleRequests(t *testing.T) {
+	got := HandleRequests(context.Background(), []string{"{\"friendly_name\":\"inline\"}", "../../internal/pkg/util/testdata/test.json"}, model.Monitor, model.Create)
+	want := []map[string]interface{}{{
+		model.ErrorResultField:       fmt.Errorf(monitor.MsgFieldMissing, httputil.TypeField),
+		model.MonitorNameResultField: "inline",
+	}, {
+		model.ErrorResultField:       fmt.Errorf(monitor.MsgFieldMissing, httputil.TypeField),
+		model.MonitorNameResultField: "test",
+		model.SourceResultField:      "../../internal/pkg/util/testdata/test.json[0]",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleRequests() = %v, want %v", got, want)
+	}
+
+	got = HandleRequests(context.Background(), []string{"../../internal/pkg/util/testdata/test.json"}, model.Monitor, model.Create)
+	want = []map[string]interface{}{{
+		model.ErrorResultField:       fmt.Errorf(monitor.MsgFieldMissing, httputil.TypeField),
+		model.MonitorNameResultField: "test",
+		model.SourceResultField:      "../../internal/pkg/util/testdata/test.json[0]",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleRequests() = %v, want %v", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got = HandleRequests(ctx, []string{"{\"friendly_name\":\"test\",\"type\":\"HTTP\"}"}, model.Monitor, model.Create)
+	want = []map[string]interface{}{nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleRequests() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+package model
+
+// AlertContactType is how an alert contact is notified, it cannot be changed once the alert contact is created.
+type AlertContactType int
+
+var alertContactTypeNames = EnumNames{names: map[int]string{
+	1: "sms", 2: "email", 3: "twitter", 5: "webhook", 6: "pushbullet", 7: "zapier", 8: "pro-sms", 9: "pushover",
+	11: "slack", 14: "voice-call", 15: "splunk", 16: "pagerduty", 17: "opsgenie", 20: "ms-teams", 21: "google-chat",
+	23: "discord",
+}}
+
+func (value AlertContactType) String() string { return alertContactTypeNames.format(int(value)) }
+func (value AlertContactType) Int() int       { return int(value) }
+func (value AlertContactType) MarshalJSON() ([]byte, error) {
+	return alertContactTypeNames.marshal(int(value))
+}
+func (value *AlertContactType) UnmarshalJSON(data []byte) error {
+	parsed, err := alertContactTypeNames.unmarshal(data, "type")
+	*value = AlertContactType(parsed)
+	return err
+}
+
+// AlertContactEnums are the names of the enum fields of an alert contact, keyed by field.
+var AlertContactEnums = map[string]EnumNames{"type": alertContactTypeNames}
+
+/*
+*
+AlertContactConfig is an alert contact as accepted by the alert contact service and returned by export. value is the email
+address, phone number, webhook url etc. depending on the type.
+*/
+type AlertContactConfig struct {
+	Id           ID               `json:"id,omitempty"`
+	FriendlyName string           `json:"friendly_name,omitempty"`
+	Type         AlertContactType `json:"type,omitempty"`
+	Value        string           `json:"value,omitempty"`
+	Status       *int             `json:"status,omitempty"`
+}
+
+// Converts the alert contact into the map the alert contact service works on.
+func (alertContact AlertContactConfig) ToMap() (map[string]interface{}, error) {
+	return ToMap(alertContact)
+}
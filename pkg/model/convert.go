@@ -0,0 +1,115 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"reflect"
+	"strings"
+)
+
+/*
+*
+Converts v, e.g. a MonitorConfig, into the map the services work on. Enums are kept typed (see Enum) so that the services
+resolve and send them as the numbers the API uses, the other values are decoded the way JSON input is (numbers are
+float64).
+*/
+func ToMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dataMap := make(map[string]interface{})
+	if err := json.Unmarshal(data, &dataMap); err != nil {
+		return nil, err
+	}
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		return dataMap, nil
+	}
+	for idx := 0; idx < value.NumField(); idx++ {
+		name := strings.Split(value.Type().Field(idx).Tag.Get("json"), ",")[0]
+		if _, exists := dataMap[name]; !exists {
+			continue
+		}
+		field := reflect.Indirect(value.Field(idx))
+		if !field.IsValid() || !field.CanInterface() {
+			continue
+		}
+		if enum, ok := field.Interface().(Enum); ok {
+			dataMap[name] = enum
+		}
+	}
+	return dataMap, nil
+}
+
+/*
+*
+Converts a map returned by the services or the API into v, which needs to be a pointer e.g. *MonitorConfig. Fields that are
+not part of v are ignored.
+*/
+func FromMap(dataMap map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(dataMap)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ItemResult is the typed form of a result returned by HandleRequest for a single resource.
+type ItemResult struct {
+	Name string `json:"name"`
+	// Action is what was done to the resource e.g. created, empty when it is not known or nothing was done.
+	Action string `json:"action,omitempty"`
+	Id     string `json:"id,omitempty"`
+	// ChangedFields are the fields an update changed.
+	ChangedFields []string `json:"changed_fields,omitempty"`
+	Source        string   `json:"source,omitempty"`
+	Error         error    `json:"-"`
+	Plan          []Change `json:"plan,omitempty"`
+	// Skipped is true when the resource was not handled since the run stopped before it.
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+/*
+*
+Converts results returned by HandleRequest for resource into ItemResults, results of resources that were never handled
+are marked as skipped.
+*/
+func ItemResults(resource string, results []map[string]interface{}) []ItemResult {
+	nameField := NameResultFields[resource]
+	itemResults := make([]ItemResult, 0, len(results))
+	for _, result := range results {
+		if len(result) == 0 {
+			itemResults = append(itemResults, ItemResult{Skipped: true})
+			continue
+		}
+		var itemResult ItemResult
+		if result[nameField] != nil {
+			itemResult.Name = fmt.Sprint(result[nameField])
+		}
+		if action, ok := result[ActionResultField].(string); ok {
+			itemResult.Action = action
+		}
+		if id, ok := result[IdResultField].(string); ok {
+			itemResult.Id = id
+		}
+		if changedFields, ok := result[ChangedFieldsResultField].([]string); ok {
+			itemResult.ChangedFields = changedFields
+		}
+		if source, ok := result[SourceResultField].(string); ok {
+			itemResult.Source = source
+		}
+		if plan, ok := result[PlanResultField].([]Change); ok {
+			itemResult.Plan = plan
+		}
+		if err, ok := result[ErrorResultField].(error); ok {
+			itemResult.Error = redactutil.Error(err)
+		} else if result[ErrorResultField] != nil {
+			itemResult.Error = errors.New(redactutil.String(fmt.Sprint(result[ErrorResultField])))
+		}
+		itemResults = append(itemResults, itemResult)
+	}
+	return itemResults
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const MsgEnumValueInvalid = "%s %s is invalid"
+
+// Enum is implemented by the enum types e.g. MonitorType, String is the name used in the input and Int the number the API uses.
+type Enum interface {
+	fmt.Stringer
+	Int() int
+}
+
+/*
+*
+EnumNames holds the names of the values of an enum e.g. MonitorType. Names are used in the input and output, the values
+are the numbers the API uses. Aliases are extra names that are read but never written.
+*/
+type EnumNames struct {
+	names   map[int]string
+	aliases map[string]int
+}
+
+// Returns the name value is written as.
+func (enum EnumNames) Name(value int) (string, bool) {
+	name, ok := enum.names[value]
+	return name, ok
+}
+
+/*
+*
+Returns the number value stands for, value being one of the names (matched case-insensitively) or one of the numbers
+given as a number, a numeric string or an Enum. Unknown names and numbers as well as non-integral numbers are not
+resolved.
+*/
+func (enum EnumNames) Resolve(value interface{}) (int, bool) {
+	var number int
+	switch v := value.(type) {
+	case Enum:
+		number = v.Int()
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false
+		}
+		number = int(v)
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return enum.resolveName(v)
+		}
+		number = parsed
+	default:
+		parsed, err := strconv.Atoi(fmt.Sprint(v))
+		if err != nil {
+			return 0, false
+		}
+		number = parsed
+	}
+	_, ok := enum.names[number]
+	return number, ok
+}
+
+func (enum EnumNames) resolveName(name string) (int, bool) {
+	for value, _name := range enum.names {
+		if strings.EqualFold(_name, name) {
+			return value, true
+		}
+	}
+	for alias, value := range enum.aliases {
+		if strings.EqualFold(alias, name) {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// Formats value as its name, values without a name are formatted as numbers.
+func (enum EnumNames) format(value int) string {
+	if name, ok := enum.names[value]; ok {
+		return name
+	}
+	return strconv.Itoa(value)
+}
+
+func (enum EnumNames) marshal(value int) ([]byte, error) {
+	if name, ok := enum.names[value]; ok {
+		return json.Marshal(name)
+	}
+	return json.Marshal(value)
+}
+
+/*
+*
+Parses an enum given either as its number (as returned by the API), a numeric string or one of its names (as used in
+the input), see EnumNames.Resolve.
+*/
+func (enum EnumNames) unmarshal(data []byte, kind string) (int, error) {
+	if string(data) == "null" {
+		return 0, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return 0, fmt.Errorf(MsgEnumValueInvalid, kind, string(data))
+	}
+	number, ok := enum.Resolve(value)
+	if !ok {
+		return 0, fmt.Errorf(MsgEnumValueInvalid, kind, string(data))
+	}
+	return number, nil
+}
+
+// ID is the id of a resource, the API returns it as a number but it is accepted as a numeric string too.
+type ID int64
+
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	var number float64
+	if err := json.Unmarshal(data, &number); err == nil {
+		*id = ID(number)
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf(MsgEnumValueInvalid, "id", string(data))
+	}
+	parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return fmt.Errorf(MsgEnumValueInvalid, "id", strconv.Quote(value))
+	}
+	*id = ID(parsed)
+	return nil
+}
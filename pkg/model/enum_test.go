@@ -0,0 +1,101 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMonitorConfigJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    MonitorConfig
+		wantErr bool
+	}{
+		{name: "should read enums by name case-insensitively", data: `{"friendly_name": "api", "type": "keyword", "keyword_type": "Not Exists", "http_auth_type": "HTTP Basic Auth"}`,
+			want: MonitorConfig{FriendlyName: "api", Type: MonitorTypeKeyword, KeywordType: KeywordNotExists, HttpAuthType: HttpAuthBasic}},
+		{name: "should read enums and ids by number as returned by the API", data: `{"id": 777712827, "type": 4, "sub_type": "2", "port": 443}`,
+			want: MonitorConfig{Id: 777712827, Type: MonitorTypePort, SubType: MonitorSubTypeHTTPS, Port: 443}},
+		{name: "should read ids given as strings", data: `{"id": "777712827"}`, want: MonitorConfig{Id: 777712827}},
+		{name: "should return error on an unknown name", data: `{"type": "gRPC"}`, wantErr: true},
+		{name: "should return error on an unknown number", data: `{"type": 42}`, wantErr: true},
+		{name: "should return error on an unknown numeric string", data: `{"sub_type": "42"}`, wantErr: true},
+		{name: "should return error on a non-integral number", data: `{"type": 2.7}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got MonitorConfig
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnumNames_Resolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		want     int
+		resolved bool
+	}{
+		{name: "should resolve a name case-insensitively", value: "keyword", want: 2, resolved: true},
+		{name: "should resolve an alias", value: "HTTPS", want: 1, resolved: true},
+		{name: "should resolve a number", value: float64(4), want: 4, resolved: true},
+		{name: "should resolve a numeric string", value: "5", want: 5, resolved: true},
+		{name: "should resolve an enum", value: MonitorTypePing, want: 3, resolved: true},
+		{name: "should resolve a resolved id", value: uint8(1), want: 1, resolved: true},
+		{name: "should not resolve an unknown name", value: "gRPC"},
+		{name: "should not resolve an unknown number", value: float64(42)},
+		{name: "should not resolve a non-integral number", value: 2.7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, resolved := MonitorEnums["type"].Resolve(tt.value)
+			if resolved != tt.resolved || (resolved && got != tt.want) {
+				t.Errorf("Resolve() = %v, %v, want %v, %v", got, resolved, tt.want, tt.resolved)
+			}
+		})
+	}
+}
+
+func TestMonitorConfigToMap(t *testing.T) {
+	caseSensitive := KeywordCaseSensitive
+	got, err := MonitorConfig{Id: 777712827, FriendlyName: "api", Type: MonitorTypeKeyword, KeywordCaseType: &caseSensitive, Interval: 1000000}.ToMap()
+	if err != nil {
+		t.Fatalf("ToMap() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"id":                float64(777712827),
+		"friendly_name":     "api",
+		"type":              MonitorTypeKeyword,
+		"keyword_case_type": KeywordCaseSensitive,
+		"interval":          float64(1000000),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMap() = %v, want %v", got, want)
+	}
+}
+
+func TestItemResults(t *testing.T) {
+	results := []map[string]interface{}{
+		{ErrorResultField: nil, MonitorNameResultField: "api", ActionResultField: Created, IdResultField: "1", SourceResultField: "monitors.json"},
+		{ErrorResultField: errors.New("monitor does not exist"), MonitorNameResultField: "web"},
+		nil,
+	}
+	want := []ItemResult{
+		{Name: "api", Action: Created, Id: "1", Source: "monitors.json"},
+		{Name: "web", Error: errors.New("monitor does not exist")},
+		{Skipped: true},
+	}
+	if got := ItemResults(Monitor, results); !reflect.DeepEqual(got, want) {
+		t.Errorf("ItemResults() = %v, want %v", got, want)
+	}
+}
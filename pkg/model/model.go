@@ -1,13 +1,25 @@
 package model
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Args string
 
 const (
 	Monitor      = "monitor"
 	AlertContact = "alertcontact"
+	MWindow      = "mwindow"
+	PSP          = "psp"
 	Delete       = "delete"
 	Create       = "create"
 	Update       = "update"
+	Get          = "get"
+	List         = "list"
+	Apply        = "apply"
+	Export       = "export"
+	Restore      = "restore"
 )
 
 type Result struct {
@@ -16,6 +28,80 @@ type Result struct {
 }
 
 const (
-	ErrorResultField       = "error"
-	MonitorNameResultField = "monitor"
+	ErrorResultField            = "error"
+	DataResultField             = "data"
+	ActionResultField           = "action"
+	PlanResultField             = "plan"
+	ChangedFieldsResultField    = "changed_fields"
+	SourceResultField           = "source"
+	IdResultField               = "id"
+	MonitorNameResultField      = "monitor"
+	AlertContactNameResultField = "alert_contact"
+	MWindowNameResultField      = "mwindow"
+	PSPNameResultField          = "psp"
+)
+
+// Values of ActionResultField describing what apply did to an item.
+const (
+	Created   = "created"
+	Updated   = "updated"
+	Recreated = "recreated"
+	Deleted   = "deleted"
+	Restored  = "restored"
+	// Unchanged is reported for an update that was skipped since the monitor already matched.
+	Unchanged = "unchanged"
+)
+
+// FieldChange is the remote value of a field and the value it will be set to.
+type FieldChange struct {
+	Field   string `json:"field"`
+	Remote  string `json:"remote"`
+	Desired string `json:"desired"`
+}
+
+// Change describes what a write would do to a resource, it is reported instead of performing the write on a dry run.
+type Change struct {
+	Action string        `json:"action"`
+	Id     string        `json:"id,omitempty"`
+	Name   string        `json:"name"`
+	Fields []FieldChange `json:"fields,omitempty"`
+	// Note is what the write needs to be performed e.g. a flag, empty when nothing.
+	Note string `json:"note,omitempty"`
+}
+
+// NameResultFields maps a resource to the result field holding the name of the item acted upon.
+var NameResultFields = map[string]string{
+	Monitor:      MonitorNameResultField,
+	AlertContact: AlertContactNameResultField,
+	MWindow:      MWindowNameResultField,
+	PSP:          PSPNameResultField,
+}
+
+// SupportedActions maps a resource to the actions that can be performed on it.
+var SupportedActions = map[string][]Args{
+	Monitor:      {Create, Update, Delete, Get, List, Apply, Export, Restore},
+	AlertContact: {Create, Update, Delete, Export},
+	MWindow:      {Create, Update, Delete, Export},
+	PSP:          {Create, Update, Delete},
+}
+
+const (
+	MsgResourceNotSupported = "invalid resource %s specified"
+	MsgActionNotSupported   = "%s action is not supported on %s, the supported actions are %s"
 )
+
+// Returns an error unless resource, matched case-insensitively, is known and supports action, see SupportedActions.
+func ValidateAction(resource string, action Args) error {
+	actions, exists := SupportedActions[strings.ToLower(resource)]
+	if !exists {
+		return fmt.Errorf(MsgResourceNotSupported, resource)
+	}
+	names := make([]string, len(actions))
+	for idx, supported := range actions {
+		if supported == action {
+			return nil
+		}
+		names[idx] = string(supported)
+	}
+	return fmt.Errorf(MsgActionNotSupported, action, strings.ToLower(resource), strings.Join(names, ", "))
+}
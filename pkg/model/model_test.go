@@ -0,0 +1,23 @@
+package model
+
+import "testing"
+
+func TestValidateAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		action   Args
+		wantErr  bool
+	}{
+		{name: "should accept a supported action", resource: "Monitor", action: Restore},
+		{name: "should reject an action the resource does not support", resource: PSP, action: Export, wantErr: true},
+		{name: "should reject an unknown resource", resource: "status", action: Create, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateAction(tt.resource, tt.action); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
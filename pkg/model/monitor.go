@@ -0,0 +1,259 @@
+package model
+
+// MonitorType is the type of monitor, it cannot be changed once the monitor is created.
+type MonitorType int
+
+const (
+	MonitorTypeHTTP      MonitorType = 1
+	MonitorTypeKeyword   MonitorType = 2
+	MonitorTypePing      MonitorType = 3
+	MonitorTypePort      MonitorType = 4
+	MonitorTypeHeartbeat MonitorType = 5
+)
+
+var monitorTypeNames = EnumNames{names: map[int]string{1: "HTTP", 2: "Keyword", 3: "Ping", 4: "Port", 5: "Heartbeat"}, aliases: map[string]int{"HTTPS": 1}}
+
+func (value MonitorType) String() string { return monitorTypeNames.format(int(value)) }
+func (value MonitorType) Int() int       { return int(value) }
+func (value MonitorType) MarshalJSON() ([]byte, error) {
+	return monitorTypeNames.marshal(int(value))
+}
+func (value *MonitorType) UnmarshalJSON(data []byte) error {
+	parsed, err := monitorTypeNames.unmarshal(data, "type")
+	*value = MonitorType(parsed)
+	return err
+}
+
+// MonitorSubType is the protocol checked by a port monitor.
+type MonitorSubType int
+
+const (
+	MonitorSubTypeHTTP  MonitorSubType = 1
+	MonitorSubTypeHTTPS MonitorSubType = 2
+	MonitorSubTypeFTP   MonitorSubType = 3
+	MonitorSubTypeSMTP  MonitorSubType = 4
+	MonitorSubTypePOP3  MonitorSubType = 5
+	MonitorSubTypeIMAP  MonitorSubType = 6
+)
+
+var monitorSubTypeNames = EnumNames{names: map[int]string{1: "HTTP", 2: "HTTPS", 3: "FTP", 4: "SMTP", 5: "POP3", 6: "IMAP"}}
+
+func (value MonitorSubType) String() string { return monitorSubTypeNames.format(int(value)) }
+func (value MonitorSubType) Int() int       { return int(value) }
+func (value MonitorSubType) MarshalJSON() ([]byte, error) {
+	return monitorSubTypeNames.marshal(int(value))
+}
+func (value *MonitorSubType) UnmarshalJSON(data []byte) error {
+	parsed, err := monitorSubTypeNames.unmarshal(data, "sub_type")
+	*value = MonitorSubType(parsed)
+	return err
+}
+
+// KeywordType is whether a keyword monitor is down when the keyword exists or when it does not.
+type KeywordType int
+
+const (
+	KeywordExists    KeywordType = 1
+	KeywordNotExists KeywordType = 2
+)
+
+var keywordTypeNames = EnumNames{names: map[int]string{1: "exists", 2: "not exists"}}
+
+func (value KeywordType) String() string { return keywordTypeNames.format(int(value)) }
+func (value KeywordType) Int() int       { return int(value) }
+func (value KeywordType) MarshalJSON() ([]byte, error) {
+	return keywordTypeNames.marshal(int(value))
+}
+func (value *KeywordType) UnmarshalJSON(data []byte) error {
+	parsed, err := keywordTypeNames.unmarshal(data, "keyword_type")
+	*value = KeywordType(parsed)
+	return err
+}
+
+// KeywordCaseType is whether the keyword of a keyword monitor is case-sensitive, the zero value is case-sensitive.
+type KeywordCaseType int
+
+const (
+	KeywordCaseSensitive   KeywordCaseType = 0
+	KeywordCaseInsensitive KeywordCaseType = 1
+)
+
+var keywordCaseTypeNames = EnumNames{names: map[int]string{0: "case sensitive", 1: "case insensitive"}}
+
+func (value KeywordCaseType) String() string { return keywordCaseTypeNames.format(int(value)) }
+func (value KeywordCaseType) Int() int       { return int(value) }
+func (value KeywordCaseType) MarshalJSON() ([]byte, error) {
+	return keywordCaseTypeNames.marshal(int(value))
+}
+func (value *KeywordCaseType) UnmarshalJSON(data []byte) error {
+	parsed, err := keywordCaseTypeNames.unmarshal(data, "keyword_case_type")
+	*value = KeywordCaseType(parsed)
+	return err
+}
+
+// HttpAuthType is the authentication used by HTTP and keyword monitors.
+type HttpAuthType int
+
+const (
+	HttpAuthBasic  HttpAuthType = 1
+	HttpAuthDigest HttpAuthType = 2
+)
+
+var httpAuthTypeNames = EnumNames{names: map[int]string{1: "Basic", 2: "Digest"}, aliases: map[string]int{"HTTP Basic Auth": 1}}
+
+func (value HttpAuthType) String() string { return httpAuthTypeNames.format(int(value)) }
+func (value HttpAuthType) Int() int       { return int(value) }
+func (value HttpAuthType) MarshalJSON() ([]byte, error) {
+	return httpAuthTypeNames.marshal(int(value))
+}
+func (value *HttpAuthType) UnmarshalJSON(data []byte) error {
+	parsed, err := httpAuthTypeNames.unmarshal(data, "http_auth_type")
+	*value = HttpAuthType(parsed)
+	return err
+}
+
+// HttpMethod is the method of the requests sent by HTTP and keyword monitors.
+type HttpMethod int
+
+const (
+	HttpMethodHead    HttpMethod = 1
+	HttpMethodGet     HttpMethod = 2
+	HttpMethodPost    HttpMethod = 3
+	HttpMethodPut     HttpMethod = 4
+	HttpMethodPatch   HttpMethod = 5
+	HttpMethodDelete  HttpMethod = 6
+	HttpMethodOptions HttpMethod = 7
+)
+
+var httpMethodNames = EnumNames{names: map[int]string{1: "HEAD", 2: "GET", 3: "POST", 4: "PUT", 5: "PATCH", 6: "DELETE", 7: "OPTIONS"}}
+
+func (value HttpMethod) String() string { return httpMethodNames.format(int(value)) }
+func (value HttpMethod) Int() int       { return int(value) }
+func (value HttpMethod) MarshalJSON() ([]byte, error) {
+	return httpMethodNames.marshal(int(value))
+}
+func (value *HttpMethod) UnmarshalJSON(data []byte) error {
+	parsed, err := httpMethodNames.unmarshal(data, "http_method")
+	*value = HttpMethod(parsed)
+	return err
+}
+
+// PostType is whether the body of a monitor request is sent as key-values or as raw data.
+type PostType int
+
+const (
+	PostTypeKeyValue PostType = 1
+	PostTypeRawData  PostType = 2
+)
+
+var postTypeNames = EnumNames{names: map[int]string{1: "key-value", 2: "raw data"}}
+
+func (value PostType) String() string { return postTypeNames.format(int(value)) }
+func (value PostType) Int() int       { return int(value) }
+func (value PostType) MarshalJSON() ([]byte, error) {
+	return postTypeNames.marshal(int(value))
+}
+func (value *PostType) UnmarshalJSON(data []byte) error {
+	parsed, err := postTypeNames.unmarshal(data, "post_type")
+	*value = PostType(parsed)
+	return err
+}
+
+// PostContentType is the content type of the body of a monitor request, the zero value is text/html.
+type PostContentType int
+
+const (
+	PostContentTypeHtml PostContentType = 0
+	PostContentTypeJson PostContentType = 1
+)
+
+var postContentTypeNames = EnumNames{names: map[int]string{0: "text/html", 1: "application/json"}}
+
+func (value PostContentType) String() string { return postContentTypeNames.format(int(value)) }
+func (value PostContentType) Int() int       { return int(value) }
+func (value PostContentType) MarshalJSON() ([]byte, error) {
+	return postContentTypeNames.marshal(int(value))
+}
+func (value *PostContentType) UnmarshalJSON(data []byte) error {
+	parsed, err := postContentTypeNames.unmarshal(data, "post_content_type")
+	*value = PostContentType(parsed)
+	return err
+}
+
+// MonitorStatus is the state of a monitor, only paused (0) and not checked yet (1) can be set.
+type MonitorStatus int
+
+const (
+	MonitorPaused        MonitorStatus = 0
+	MonitorNotCheckedYet MonitorStatus = 1
+	MonitorUp            MonitorStatus = 2
+	MonitorSeemsDown     MonitorStatus = 8
+	MonitorDown          MonitorStatus = 9
+)
+
+var monitorStatusNames = EnumNames{names: map[int]string{0: "paused", 1: "not checked", 2: "up", 8: "seems down", 9: "down"}}
+
+func (value MonitorStatus) String() string { return monitorStatusNames.format(int(value)) }
+func (value MonitorStatus) Int() int       { return int(value) }
+func (value MonitorStatus) MarshalJSON() ([]byte, error) {
+	return monitorStatusNames.marshal(int(value))
+}
+func (value *MonitorStatus) UnmarshalJSON(data []byte) error {
+	parsed, err := monitorStatusNames.unmarshal(data, "status")
+	*value = MonitorStatus(parsed)
+	return err
+}
+
+// MonitorEnums are the names of the enum fields of a monitor, keyed by field. The services resolve these fields by them.
+var MonitorEnums = map[string]EnumNames{
+	"type":              monitorTypeNames,
+	"sub_type":          monitorSubTypeNames,
+	"keyword_type":      keywordTypeNames,
+	"keyword_case_type": keywordCaseTypeNames,
+	"http_auth_type":    httpAuthTypeNames,
+	"http_method":       httpMethodNames,
+	"post_type":         postTypeNames,
+	"post_content_type": postContentTypeNames,
+}
+
+// MonitorStatuses are the names of the statuses of a monitor, list filters monitors by them.
+var MonitorStatuses = monitorStatusNames
+
+/*
+*
+MonitorConfig is a monitor as accepted by the monitor service and returned by list/get/export. Enums are written by name e.g.
+"type": "HTTP" and read either by name or by the number the API uses. Fields that are not set are left out, which for
+update means they are not changed. alert_contacts and mwindows use the delimited formats of the API e.g.
+"0993765_0_0-2978654_0_0".
+*/
+type MonitorConfig struct {
+	Id              ID               `json:"id,omitempty"`
+	FriendlyName    string           `json:"friendly_name,omitempty"`
+	Url             string           `json:"url,omitempty"`
+	Type            MonitorType      `json:"type,omitempty"`
+	SubType         MonitorSubType   `json:"sub_type,omitempty"`
+	Port            int              `json:"port,omitempty"`
+	KeywordType     KeywordType      `json:"keyword_type,omitempty"`
+	KeywordCaseType *KeywordCaseType `json:"keyword_case_type,omitempty"`
+	KeywordValue    string           `json:"keyword_value,omitempty"`
+	Interval        int              `json:"interval,omitempty"`
+	Timeout         int              `json:"timeout,omitempty"`
+	HttpUsername    string           `json:"http_username,omitempty"`
+	HttpPassword    string           `json:"http_password,omitempty"`
+	HttpAuthType    HttpAuthType     `json:"http_auth_type,omitempty"`
+	HttpMethod      HttpMethod       `json:"http_method,omitempty"`
+	PostType        PostType         `json:"post_type,omitempty"`
+	// PostValue is the body of the requests, objects are sent as JSON.
+	PostValue          interface{}       `json:"post_value,omitempty"`
+	PostContentType    *PostContentType  `json:"post_content_type,omitempty"`
+	AlertContacts      string            `json:"alert_contacts,omitempty"`
+	MWindows           string            `json:"mwindows,omitempty"`
+	CustomHttpHeaders  map[string]string `json:"custom_http_headers,omitempty"`
+	CustomHttpStatuses string            `json:"custom_http_statuses,omitempty"`
+	Status             *MonitorStatus    `json:"status,omitempty"`
+}
+
+// Converts the monitor into the map the monitor service works on.
+func (monitor MonitorConfig) ToMap() (map[string]interface{}, error) {
+	return ToMap(monitor)
+}
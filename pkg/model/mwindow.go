@@ -0,0 +1,48 @@
+package model
+
+// MWindowType is how often a maintenance window recurs, it cannot be changed once the window is created.
+type MWindowType int
+
+const (
+	MWindowOnce    MWindowType = 1
+	MWindowDaily   MWindowType = 2
+	MWindowWeekly  MWindowType = 3
+	MWindowMonthly MWindowType = 4
+)
+
+var mWindowTypeNames = EnumNames{names: map[int]string{1: "once", 2: "daily", 3: "weekly", 4: "monthly"}}
+
+func (value MWindowType) String() string { return mWindowTypeNames.format(int(value)) }
+func (value MWindowType) Int() int       { return int(value) }
+func (value MWindowType) MarshalJSON() ([]byte, error) {
+	return mWindowTypeNames.marshal(int(value))
+}
+func (value *MWindowType) UnmarshalJSON(data []byte) error {
+	parsed, err := mWindowTypeNames.unmarshal(data, "type")
+	*value = MWindowType(parsed)
+	return err
+}
+
+// MWindowEnums are the names of the enum fields of a maintenance window, keyed by field.
+var MWindowEnums = map[string]EnumNames{"type": mWindowTypeNames}
+
+/*
+*
+MWindowConfig is a maintenance window as accepted by the maintenance window service and returned by export. start_time is a
+unix timestamp or date for once windows and HH:mm otherwise, value holds the days of weekly and monthly windows e.g.
+"1-3-5". duration is in minutes.
+*/
+type MWindowConfig struct {
+	Id           ID          `json:"id,omitempty"`
+	FriendlyName string      `json:"friendly_name,omitempty"`
+	Type         MWindowType `json:"type,omitempty"`
+	Value        string      `json:"value,omitempty"`
+	StartTime    interface{} `json:"start_time,omitempty"`
+	Duration     int         `json:"duration,omitempty"`
+	Status       *int        `json:"status,omitempty"`
+}
+
+// Converts the maintenance window into the map the maintenance window service works on.
+func (mWindow MWindowConfig) ToMap() (map[string]interface{}, error) {
+	return ToMap(mWindow)
+}
@@ -0,0 +1,38 @@
+package model
+
+// PSPSort is the order the monitors of a public status page are listed in.
+type PSPSort int
+
+var pspSortNames = EnumNames{names: map[int]string{
+	1: "a-z", 2: "z-a", 3: "status-up-down-paused", 4: "status-down-up-paused",
+}}
+
+func (value PSPSort) String() string { return pspSortNames.format(int(value)) }
+func (value PSPSort) Int() int       { return int(value) }
+func (value PSPSort) MarshalJSON() ([]byte, error) {
+	return pspSortNames.marshal(int(value))
+}
+func (value *PSPSort) UnmarshalJSON(data []byte) error {
+	parsed, err := pspSortNames.unmarshal(data, "sort")
+	*value = PSPSort(parsed)
+	return err
+}
+
+// PSPStatus is whether a public status page is shown.
+type PSPStatus int
+
+var pspStatusNames = EnumNames{names: map[int]string{0: "paused", 1: "active"}}
+
+func (value PSPStatus) String() string { return pspStatusNames.format(int(value)) }
+func (value PSPStatus) Int() int       { return int(value) }
+func (value PSPStatus) MarshalJSON() ([]byte, error) {
+	return pspStatusNames.marshal(int(value))
+}
+func (value *PSPStatus) UnmarshalJSON(data []byte) error {
+	parsed, err := pspStatusNames.unmarshal(data, "status")
+	*value = PSPStatus(parsed)
+	return err
+}
+
+// PSPEnums are the names of the enum fields of a public status page, keyed by field.
+var PSPEnums = map[string]EnumNames{"sort": pspSortNames, "status": pspStatusNames}
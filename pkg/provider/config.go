@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	UptimeRobotConfigEnv  = "UPTIME_ROBOT_CONFIG"
+	UptimeRobotProfileEnv = "UPTIME_ROBOT_PROFILE"
+)
+
+// DefaultProfile is used when no profile is selected, a config file without it leaves the configuration to the env.
+const DefaultProfile = "default"
+
+const (
+	MsgConfigInvalid      = "config file %s is invalid: %v"
+	MsgProfileNotFound    = "profile %s is not in config file %s, the profiles are %s"
+	MsgProfileEnvInvalid  = "%s in profile %s of config file %s is not an env variable name"
+	MsgConfigFileNotFound = "config file %s does not exist"
+	MsgProfileOverridden  = "%s of the env overrides %s of profile %s in config file %s"
+)
+
+/*
+*
+Variables that configure the same thing in different ways. Setting one of them in the env overrides all of them in the
+profile, e.g. an exported UPTIME_ROBOT_API_KEY is used over the UPTIME_ROBOT_API_KEY_FILE of the profile.
+*/
+var alternativeEnvs = [][]string{
+	{"UPTIME_ROBOT_API_KEY", "UPTIME_ROBOT_API_KEY_FILE"},
+}
+
+/*
+*
+Config is the YAML config file, named profiles holding env variables e.g.
+
+	profiles:
+	  prod:
+	    UPTIME_ROBOT_API_KEY_FILE: /etc/uptimerobot/prod.key
+	    MONITOR_ALERT_CONTACTS_DELIMITER: "-"
+*/
+type Config struct {
+	Profiles map[string]map[string]string `yaml:"profiles"`
+}
+
+/*
+*
+ProfileConfigProvider looks variables up in the env first and then in the profile of a config file, so that flags (which
+are passed on as env variables) and exported variables take precedence over the profile.
+*/
+type ProfileConfigProvider struct {
+	Path    string
+	Profile string
+	// Explicit tells whether Profile was selected rather than being DefaultProfile.
+	Explicit bool
+	values   map[string]string
+}
+
+func (p *ProfileConfigProvider) LookUpEnv(variable string) (string, bool) {
+	if value, found := os.LookupEnv(variable); found {
+		return value, true
+	}
+	for _, alternatives := range alternativeEnvs {
+		if !contains(alternatives, variable) {
+			continue
+		}
+		for _, alternative := range alternatives {
+			if _, found := os.LookupEnv(alternative); found {
+				return "", false
+			}
+		}
+	}
+	value, found := p.values[variable]
+	return value, found
+}
+
+/*
+*
+Returns the variables of the profile that the env overrides by the env variables overriding them, see LookUpEnv e.g.
+UPTIME_ROBOT_API_KEY_FILE: UPTIME_ROBOT_API_KEY.
+*/
+func (p *ProfileConfigProvider) Overrides() map[string]string {
+	overrides := make(map[string]string)
+	for variable := range p.values {
+		if _, found := os.LookupEnv(variable); found {
+			overrides[variable] = variable
+			continue
+		}
+		for _, alternatives := range alternativeEnvs {
+			if !contains(alternatives, variable) {
+				continue
+			}
+			for _, alternative := range alternatives {
+				if _, found := os.LookupEnv(alternative); found {
+					overrides[variable] = alternative
+				}
+			}
+		}
+	}
+	return overrides
+}
+
+var _ IConfigProvider = &ProfileConfigProvider{}
+
+/*
+*
+Loads profile from the config file at path and makes it the Default provider. An empty path is UPTIME_ROBOT_CONFIG or
+config.yaml in uptimerobot-tooling in the user config directory e.g. ~/.config/uptimerobot-tooling/config.yaml, which
+may not exist. An empty profile is UPTIME_ROBOT_PROFILE or DefaultProfile, which may not be in the config file. Returns
+nil when there is no config to load.
+*/
+func LoadConfig(path string, profile string) (*ProfileConfigProvider, error) {
+	explicitPath := path != ""
+	if !explicitPath {
+		path, explicitPath = os.LookupEnv(UptimeRobotConfigEnv)
+	}
+	if !explicitPath {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(configDir, "uptimerobot-tooling", "config.yaml")
+	}
+	explicitProfile := profile != ""
+	if !explicitProfile {
+		profile, explicitProfile = os.LookupEnv(UptimeRobotProfileEnv)
+	}
+	if !explicitProfile {
+		profile = DefaultProfile
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if explicitPath {
+			return nil, fmt.Errorf(MsgConfigFileNotFound, path)
+		} else if explicitProfile {
+			return nil, fmt.Errorf(MsgProfileNotFound, profile, path, "none")
+		}
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf(MsgConfigInvalid, path, err)
+	}
+
+	values, found := config.Profiles[profile]
+	if !found {
+		if explicitProfile {
+			return nil, fmt.Errorf(MsgProfileNotFound, profile, path, config.profileNames())
+		}
+		return nil, nil
+	}
+	for variable := range values {
+		if !isEnvName(variable) {
+			return nil, fmt.Errorf(MsgProfileEnvInvalid, variable, profile, path)
+		}
+	}
+	configProvider := &ProfileConfigProvider{Path: path, Profile: profile, Explicit: explicitProfile, values: values}
+	Default = configProvider
+	return configProvider, nil
+}
+
+// Returns the names of the profiles sorted and comma separated, none when there are no profiles.
+func (config Config) profileNames() string {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Returns whether name is made of upper case letters, digits and underscores like the env variables of the tool.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, char := range name {
+		if (char < 'A' || char > 'Z') && (char < '0' || char > '9') && char != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testConfig = `profiles:
+  prod:
+    UPTIME_ROBOT_API_KEY_FILE: /etc/uptimerobot/prod.key
+    MONITOR_DRY_RUN: true
+    MONITOR_ALERT_CONTACTS_DELIMITER: "-"
+  default:
+    UPTIME_ROBOT_API_KEY: staging-api-key
+`
+
+func writeConfig(t *testing.T, dir string, content string) string {
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	configPath := writeConfig(t, t.TempDir(), testConfig)
+	invalidPath := writeConfig(t, t.TempDir(), "profiles:\n  prod:\n    api_key: value\n")
+
+	tests := []struct {
+		name        string
+		path        string
+		profile     string
+		env         map[string]string
+		wantProfile string
+		wantErr     bool
+	}{
+		{name: "should load the selected profile", path: configPath, profile: "prod", wantProfile: "prod"},
+		{name: "should load the default profile when none is selected", path: configPath, wantProfile: DefaultProfile},
+		{name: "should take the config file and profile from the env", env: map[string]string{
+			UptimeRobotConfigEnv: configPath, UptimeRobotProfileEnv: "prod",
+		}, wantProfile: "prod"},
+		{name: "should fail if the selected profile does not exist", path: configPath, profile: "dev", wantErr: true},
+		{name: "should fail if the config file does not exist", path: filepath.Join(t.TempDir(), "config.yaml"), wantErr: true},
+		{name: "should fail if a profile holds something else than env variables", path: invalidPath, profile: "prod", wantErr: true},
+		{name: "should load nothing without a config file in the user config directory"},
+		{name: "should fail if a profile is selected without a config file", profile: "prod", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the default config file is looked for in an empty user config directory
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("HOME", t.TempDir())
+			for variable, value := range tt.env {
+				t.Setenv(variable, value)
+			}
+			defaultProvider := Default
+			t.Cleanup(func() { Default = defaultProvider })
+
+			got, err := LoadConfig(tt.path, tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantProfile == "" {
+				if got != nil || Default != defaultProvider {
+					t.Errorf("LoadConfig() got = %v, want no profile loaded", got)
+				}
+				return
+			}
+			if got.Profile != tt.wantProfile || Default != got {
+				t.Errorf("LoadConfig() got profile %s, want %s as the default provider", got.Profile, tt.wantProfile)
+			}
+		})
+	}
+}
+
+func TestProfileConfigProvider_Overrides(t *testing.T) {
+	configProvider := &ProfileConfigProvider{Profile: "prod", values: map[string]string{
+		"UPTIME_ROBOT_API_KEY_FILE":        "/etc/uptimerobot/prod.key",
+		"MONITOR_DRY_RUN":                  "true",
+		"MONITOR_ALERT_CONTACTS_DELIMITER": "-",
+	}}
+	for _, variable := range []string{"MONITOR_ALERT_CONTACTS_DELIMITER", "UPTIME_ROBOT_API_KEY_FILE"} {
+		t.Setenv(variable, "")
+		os.Unsetenv(variable)
+	}
+	t.Setenv("MONITOR_DRY_RUN", "false")
+	t.Setenv("UPTIME_ROBOT_API_KEY", "staging-api-key")
+
+	want := map[string]string{"MONITOR_DRY_RUN": "MONITOR_DRY_RUN", "UPTIME_ROBOT_API_KEY_FILE": "UPTIME_ROBOT_API_KEY"}
+	if got := configProvider.Overrides(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Overrides() = %v, want %v", got, want)
+	}
+}
+
+func TestProfileConfigProvider_LookUpEnv(t *testing.T) {
+	configProvider := &ProfileConfigProvider{Profile: "prod", values: map[string]string{
+		"UPTIME_ROBOT_API_KEY_FILE":        "/etc/uptimerobot/prod.key",
+		"MONITOR_DRY_RUN":                  "true",
+		"MONITOR_ALERT_CONTACTS_DELIMITER": "-",
+	}}
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		variable  string
+		want      string
+		wantFound bool
+	}{
+		{name: "should fall back to the profile", variable: "MONITOR_DRY_RUN", want: "true", wantFound: true},
+		{name: "should prefer the env over the profile", env: map[string]string{"MONITOR_DRY_RUN": "false"}, variable: "MONITOR_DRY_RUN", want: "false", wantFound: true},
+		{name: "should not find variables set nowhere", variable: "MONITOR_CONCURRENCY"},
+		{name: "should let the env API key override the key file of the profile", env: map[string]string{"UPTIME_ROBOT_API_KEY": "env-api-key"}, variable: "UPTIME_ROBOT_API_KEY_FILE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, variable := range []string{"MONITOR_DRY_RUN", "MONITOR_CONCURRENCY", "UPTIME_ROBOT_API_KEY", "UPTIME_ROBOT_API_KEY_FILE"} {
+				t.Setenv(variable, "")
+				os.Unsetenv(variable)
+			}
+			for variable, value := range tt.env {
+				t.Setenv(variable, value)
+			}
+
+			got, found := configProvider.LookUpEnv(tt.variable)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("LookUpEnv() = %v, %v, want %v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package provider
 
+import "os"
+
 type IConfigProvider interface {
 	LookUpEnv(variable string) (string, bool)
 }
@@ -13,3 +15,24 @@ func (n *NopConfigProvider) LookUpEnv(variable string) (string, bool) {
 }
 
 var _ IConfigProvider = &NopConfigProvider{}
+
+// EnvConfigProvider looks the configuration up in the environment.
+type EnvConfigProvider struct{}
+
+func (e *EnvConfigProvider) LookUpEnv(variable string) (string, bool) {
+	return os.LookupEnv(variable)
+}
+
+var _ IConfigProvider = &EnvConfigProvider{}
+
+/*
+*
+Default is where the services look their configuration up, the environment unless a config file profile is loaded (see
+LoadConfig).
+*/
+var Default IConfigProvider = &EnvConfigProvider{}
+
+// LookUpEnv looks variable up in Default.
+func LookUpEnv(variable string) (string, bool) {
+	return Default.LookUpEnv(variable)
+}
@@ -0,0 +1,293 @@
+package alertcontact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/provider"
+	"github.com/onaio/uptimerobot-tooling/pkg/service"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+)
+
+const (
+	AlertContactResolveByFriendlyNameEnv = "ALERT_CONTACT_RESOLVE_BY_FRIENDLY_NAME"
+)
+
+const (
+	MsgAlertContactDoesNotExist                    = "alert contact does not exist"
+	MsgFieldMissing                                = "%s needs to be specified"
+	MsgMappingNotFound                             = "mapping for %s -> %s not found"
+	MsgActionNotSupported                          = "%s action is not supported"
+	MsgOriginalAndProvidedAlertContactConflictType = "original and provided alert contact have conflicting types (orig: %v and provided: %v), the type of an alert contact cannot be edited"
+	MsgAmbiguousAlertContact                       = "more than one alert contact is named %s (ids %s), specify the id"
+)
+
+func (service *AlertContactService) HandleRequest(ctx context.Context, dataMapInterface []map[string]interface{}, action model.Args) []map[string]interface{} {
+	service.ctx = ctx
+	if action == model.Export {
+		alertContacts, err := service.ExportRequest()
+		resultArrayMap := createResultObject(model.Result{ErrorResultField: err}, 0, []map[string]interface{}{{}})
+		if err == nil {
+			resultArrayMap[0][model.DataResultField] = alertContacts
+		}
+		return resultArrayMap
+	}
+
+	resultArrayMap := make([]map[string]interface{}, len(dataMapInterface))
+	for idx, dataMap := range dataMapInterface {
+		if ctx.Err() != nil {
+			// cancelled, the remaining items are left unhandled
+			break
+		}
+		resultArrayMap[idx] = make(map[string]interface{})
+		if action == model.Create || action == model.Update {
+			if err := service.isValidPayload(dataMap, action); err != nil {
+				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+				return resultArrayMap
+			}
+
+			if err := service.resolveAlertContactProperties(dataMap); err != nil {
+				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+				return resultArrayMap
+			}
+
+			if action == model.Update {
+				if err := service.UpdateRequest(dataMap); err != nil {
+					resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+					return resultArrayMap
+				}
+			} else {
+				if err := service.CreateRequest(dataMap); err != nil {
+					resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+					return resultArrayMap
+				}
+			}
+			resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+		} else if action == model.Delete {
+			log.Infof("deleting %v alert contact", dataMap[httputil.FriendlyNameField])
+
+			if err := service.DeleteRequest(dataMap); err != nil {
+				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+				return resultArrayMap
+			}
+			resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+
+		} else {
+			log.Fatalf(MsgActionNotSupported, action)
+		}
+	}
+
+	return resultArrayMap
+}
+
+/*
+*
+Populates resultMap on an index with error and alert contact name.
+*/
+func createResultObject(result model.Result, index int, resultArrayMap []map[string]interface{}) []map[string]interface{} {
+	resultArrayMap[index][model.ErrorResultField] = result.ErrorResultField
+	if result.NameResultField != nil {
+		resultArrayMap[index][model.AlertContactNameResultField] = fmt.Sprint(result.NameResultField)
+	} else {
+		resultArrayMap[index][model.AlertContactNameResultField] = ""
+	}
+	return resultArrayMap
+}
+
+/*
+*
+Checks if the payload supplied is valid i.e.
+On update: friendly_name or id needs to be preset.
+On creation: friendly_name, type and value need to be specified.
+*/
+func (service *AlertContactService) isValidPayload(dataMap map[string]interface{}, args model.Args) error {
+	if args == model.Update {
+		if dataMap[httputil.FriendlyNameField] == nil && dataMap[httputil.IdField] == nil {
+			return fmt.Errorf("%s or %s is needed for an update/create", httputil.FriendlyNameField, httputil.IdField)
+		}
+		return nil
+	}
+
+	for _, field := range []string{httputil.FriendlyNameField, httputil.TypeField, httputil.ValueField} {
+		if dataMap[field] == nil {
+			return fmt.Errorf(MsgFieldMissing, field)
+		}
+	}
+	return nil
+}
+
+/*
+*
+Resolves alert contact properties mapped on model.AlertContactEnums e.g. email -> 2. Type names are matched
+case-insensitively, the ids they map to are accepted as is.
+*/
+func (service *AlertContactService) resolveAlertContactProperties(dataMap map[string]interface{}) error {
+	for property, value := range dataMap {
+		if enum, exists := model.AlertContactEnums[property]; exists {
+			id, resolved := enum.Resolve(value)
+			if !resolved {
+				return fmt.Errorf(MsgMappingNotFound, property, fmt.Sprint(value))
+			}
+			dataMap[property] = uint8(id)
+		}
+	}
+	return nil
+}
+
+func (service *AlertContactService) UpdateRequest(dataMap map[string]interface{}) error {
+	originalAlertContact, err := service.findAlertContact(dataMap)
+	if err != nil {
+		return err
+	}
+
+	if originalAlertContact != nil {
+		log.Infof("%s %s %s", "updating", dataMap[httputil.FriendlyNameField], "alert contact")
+		dataMap[httputil.IdField] = originalAlertContact[httputil.IdField]
+
+		if dataMap[httputil.TypeField] != nil && fmt.Sprint(dataMap[httputil.TypeField]) != fmt.Sprint(originalAlertContact[httputil.TypeField]) {
+			return fmt.Errorf(MsgOriginalAndProvidedAlertContactConflictType, originalAlertContact[httputil.TypeField], dataMap[httputil.TypeField])
+		}
+
+		editData := make(map[string]interface{})
+		for _, field := range []string{httputil.IdField, httputil.FriendlyNameField, httputil.ValueField} {
+			if dataMap[field] != nil {
+				editData[field] = dataMap[field]
+			}
+		}
+		if _, err := service.InitiateRequest(httputil.EditAlertContactEndpoint, editData); err != nil {
+			return err
+		}
+	} else {
+		if err := service.isValidPayload(dataMap, model.Create); err != nil {
+			return err
+		}
+		if err := service.CreateRequest(dataMap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (service *AlertContactService) CreateRequest(dataMap map[string]interface{}) error {
+	log.Infof("%s %s %s", "creating", dataMap[httputil.FriendlyNameField], "alert contact")
+	if _, err := service.InitiateRequest(httputil.NewAlertContactEndpoint, dataMap); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (service *AlertContactService) DeleteRequest(dataMap map[string]interface{}) error {
+	if dataMap[httputil.IdField] == nil && dataMap[httputil.FriendlyNameField] == nil {
+		return fmt.Errorf(MsgFieldMissing, "id or friendly_name")
+	}
+
+	remoteAlertContact, err := service.findAlertContact(dataMap)
+	if err != nil {
+		return err
+	}
+	if remoteAlertContact == nil {
+		return errors.New(MsgAlertContactDoesNotExist)
+	}
+
+	_, err = service.InitiateRequest(httputil.DeleteAlertContactEndpoint, map[string]interface{}{
+		httputil.IdField: remoteAlertContact[httputil.IdField],
+	})
+	return err
+}
+
+/*
+*
+Looks up the remote alert contact by id if present otherwise by friendly_name (unless ALERT_CONTACT_RESOLVE_BY_FRIENDLY_NAME
+is false). Returns nil when no alert contact matches and an error listing the candidate ids when more than one alert
+contact has the friendly_name.
+*/
+func (service *AlertContactService) findAlertContact(dataMap map[string]interface{}) (map[string]interface{}, error) {
+	if dataMap[httputil.IdField] != nil {
+		alertContacts, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetAlertContactsEndpoint, httputil.AlertContactsField, map[string]interface{}{
+			httputil.AlertContactsField: dataMap[httputil.IdField],
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, alertContact := range alertContacts {
+			if serviceutil.ToString(alertContact[httputil.IdField]) == serviceutil.ToString(dataMap[httputil.IdField]) {
+				return alertContact, nil
+			}
+		}
+		return nil, nil
+	}
+
+	if _, err := service.alertContactResolvableByFriendlyName(); err != nil {
+		return nil, err
+	}
+
+	alertContacts, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetAlertContactsEndpoint, httputil.AlertContactsField, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	var candidates []map[string]interface{}
+	for _, alertContact := range alertContacts {
+		if fmt.Sprint(alertContact[httputil.FriendlyNameField]) == fmt.Sprint(dataMap[httputil.FriendlyNameField]) {
+			candidates = append(candidates, alertContact)
+		}
+	}
+	if len(candidates) > 1 {
+		return nil, fmt.Errorf(MsgAmbiguousAlertContact, dataMap[httputil.FriendlyNameField], serviceutil.JoinIds(candidates))
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return nil, nil
+}
+
+func (service *AlertContactService) alertContactResolvableByFriendlyName() (bool, error) {
+	val, found := service.IService.LookUpEnv(AlertContactResolveByFriendlyNameEnv)
+	if found {
+		parseBool, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, err
+		}
+
+		if !parseBool {
+			return false, fmt.Errorf("id field missing but one can enable %s env to update by friendly_name", AlertContactResolveByFriendlyNameEnv)
+		}
+
+	}
+	return true, nil
+}
+
+func (service *AlertContactService) InitiateRequest(endpoint string, data map[string]interface{}) (map[string]interface{}, error) {
+	return serviceutil.InitiateRequest(service.requestContext(), service.IService, endpoint, data)
+}
+
+func New() *AlertContactService {
+	alertcontactservice := &AlertContactService{}
+	alertcontactservice.IService = alertcontactservice
+	return alertcontactservice
+}
+
+type AlertContactService struct {
+	service.IService
+	// ctx is the context of the request being handled.
+	ctx context.Context
+}
+
+func (service *AlertContactService) HttpInitiatePostRequest(ctx context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	return httputil.New().InitiatePostRequest(ctx, endpoint, dataMap)
+}
+
+// Returns the context of the request being handled, see HandleRequest.
+func (service *AlertContactService) requestContext() context.Context {
+	if service.ctx == nil {
+		return context.Background()
+	}
+	return service.ctx
+}
+
+func (service *AlertContactService) LookUpEnv(variable string) (string, bool) {
+	return provider.LookUpEnv(variable)
+}
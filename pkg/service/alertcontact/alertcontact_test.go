@@ -0,0 +1,278 @@
+package alertcontact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/service"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"reflect"
+	"testing"
+)
+
+type testAlertContactService struct {
+	service.IService
+	mock.Mock
+}
+
+func (t *testAlertContactService) HttpInitiatePostRequest(_ context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	args := t.Called(endpoint, dataMap)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func (t *testAlertContactService) LookUpEnv(variable string) (string, bool) {
+	args := t.Called(variable)
+	return args.String(0), args.Bool(1)
+}
+
+var alertContactsResponse = map[string]interface{}{
+	"stat":   "ok",
+	"offset": float64(0),
+	"limit":  float64(50),
+	"total":  float64(2),
+	"alert_contacts": []interface{}{
+		map[string]interface{}{
+			"id":            "1",
+			"friendly_name": "ops@email.com",
+			"type":          float64(2),
+			"value":         "ops@email.com",
+		},
+		map[string]interface{}{
+			"id":            "2",
+			"friendly_name": "ops-slack",
+			"type":          float64(11),
+			"value":         "https://hooks.slack.com/services/x",
+		},
+	},
+}
+
+func TestAlertContactService_HandleRequest(t *testing.T) {
+	var testalertcontactservice *testAlertContactService
+	alertcontactservice := &AlertContactService{}
+
+	type args struct {
+		data     []map[string]interface{}
+		argument model.Args
+	}
+	tests := []struct {
+		name        string
+		args        args
+		setupMocks  func()
+		verifyMocks func()
+		want        []map[string]interface{}
+	}{
+		{name: "should return error when given JSON Object with missing fields", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "test",
+		}}, model.Create}, want: []map[string]interface{}{{
+			model.ErrorResultField:            fmt.Errorf(MsgFieldMissing, httputil.TypeField),
+			model.AlertContactNameResultField: "test",
+		}}},
+		{name: "should return error when type cannot be mapped", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "test",
+			httputil.TypeField:         "carrier-pigeon",
+			httputil.ValueField:        "coop",
+		}}, model.Create}, want: []map[string]interface{}{{
+			model.ErrorResultField:            fmt.Errorf(MsgMappingNotFound, httputil.TypeField, "carrier-pigeon"),
+			model.AlertContactNameResultField: "test",
+		}}},
+		{name: "should create alert contact with resolved type", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.NewAlertContactEndpoint, map[string]interface{}{
+				httputil.FriendlyNameField: "test",
+				httputil.TypeField:         uint8(2),
+				httputil.ValueField:        "test@email.com",
+			}).Return(map[string]interface{}{}, nil)
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "test",
+			httputil.TypeField:         "Email",
+			httputil.ValueField:        "test@email.com",
+		}}, model.Create}, want: []map[string]interface{}{{model.ErrorResultField: nil, model.AlertContactNameResultField: "test"}}},
+		{name: "should return result payload with non-nil err field on delete", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			testalertcontactservice.On("LookUpEnv", AlertContactResolveByFriendlyNameEnv).Return("", false)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(alertContactsResponse, nil)
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "missing",
+		}}, model.Delete}, want: []map[string]interface{}{{model.ErrorResultField: errors.New(MsgAlertContactDoesNotExist), model.AlertContactNameResultField: "missing"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if got := alertcontactservice.HandleRequest(context.Background(), tt.args.data, tt.args.argument); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HandleRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertContactService_UpdateRequest(t *testing.T) {
+	var testalertcontactservice *testAlertContactService
+	alertcontactservice := &AlertContactService{}
+
+	type args struct {
+		dataMap map[string]interface{}
+	}
+	tests := []struct {
+		name        string
+		args        args
+		setupMocks  func()
+		verifyMocks func()
+		wantErr     bool
+	}{
+		{name: "should edit alert contact resolved by friendly_name", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			testalertcontactservice.On("LookUpEnv", AlertContactResolveByFriendlyNameEnv).Return("", false)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(alertContactsResponse, nil)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.EditAlertContactEndpoint, map[string]interface{}{
+				httputil.IdField:           "2",
+				httputil.FriendlyNameField: "ops-slack",
+				httputil.ValueField:        "https://hooks.slack.com/services/y",
+			}).Return(map[string]interface{}{}, nil)
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "ops-slack",
+			httputil.TypeField:         uint8(11),
+			httputil.ValueField:        "https://hooks.slack.com/services/y",
+		}}, wantErr: false},
+		{name: "should create alert contact when it does not exist", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			testalertcontactservice.On("LookUpEnv", AlertContactResolveByFriendlyNameEnv).Return("true", true)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(alertContactsResponse, nil)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.NewAlertContactEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "dev@email.com",
+			httputil.TypeField:         uint8(2),
+			httputil.ValueField:        "dev@email.com",
+		}}, wantErr: false},
+		{name: "should return error when type differs from remote alert contact", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			testalertcontactservice.On("LookUpEnv", AlertContactResolveByFriendlyNameEnv).Return("", false)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(alertContactsResponse, nil)
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "ops@email.com",
+			httputil.TypeField:         uint8(1),
+		}}, wantErr: true},
+		{name: "should fail to resolve by friendly_name when config set to false", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			testalertcontactservice.On("LookUpEnv", AlertContactResolveByFriendlyNameEnv).Return("false", true)
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "ops@email.com",
+		}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if err := alertcontactservice.UpdateRequest(tt.args.dataMap); (err != nil) != tt.wantErr {
+				t.Errorf("UpdateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAlertContactService_DeleteRequest(t *testing.T) {
+	var testalertcontactservice *testAlertContactService
+	alertcontactservice := &AlertContactService{}
+
+	type args struct {
+		dataMap map[string]interface{}
+	}
+	tests := []struct {
+		name        string
+		args        args
+		setupMocks  func()
+		verifyMocks func()
+		wantErr     bool
+	}{
+		{name: "should return error when id or friendly_name field is absent", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{}}, wantErr: true},
+		{name: "should delete alert contact resolved by id", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(alertContactsResponse, nil)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.DeleteAlertContactEndpoint, map[string]interface{}{
+				httputil.IdField: "1",
+			}).Return(map[string]interface{}{}, nil)
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.IdField: "1",
+		}}, wantErr: false},
+		{name: "should delete alert contact resolved by friendly_name", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			testalertcontactservice.On("LookUpEnv", AlertContactResolveByFriendlyNameEnv).Return("", false)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(alertContactsResponse, nil)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.DeleteAlertContactEndpoint, map[string]interface{}{
+				httputil.IdField: "2",
+			}).Return(map[string]interface{}{}, nil)
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "ops-slack",
+		}}, wantErr: false},
+		{name: "should return error when more than one alert contact has the friendly_name", setupMocks: func() {
+			testalertcontactservice = &testAlertContactService{}
+			testalertcontactservice.On("LookUpEnv", AlertContactResolveByFriendlyNameEnv).Return("", false)
+			testalertcontactservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+				"stat": "ok",
+				"alert_contacts": []interface{}{
+					map[string]interface{}{"id": "2", "friendly_name": "ops-slack", "type": float64(11)},
+					map[string]interface{}{"id": "3", "friendly_name": "ops-slack", "type": float64(11)},
+				},
+			}, nil)
+			alertcontactservice.IService = testalertcontactservice
+		}, verifyMocks: func() {
+			testalertcontactservice.AssertExpectations(t)
+			testalertcontactservice.AssertNotCalled(t, "HttpInitiatePostRequest", httputil.DeleteAlertContactEndpoint, mock.Anything)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "ops-slack",
+		}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if err := alertcontactservice.DeleteRequest(tt.args.dataMap); (err != nil) != tt.wantErr {
+				t.Errorf("DeleteRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package alertcontact
+
+import (
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+)
+
+/*
+*
+Returns every alert contact in the account as friendly_name, type (mapped back to its name) and value so that it can be
+applied to this or another account.
+*/
+func (service *AlertContactService) ExportRequest() ([]map[string]interface{}, error) {
+	remoteAlertContacts, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetAlertContactsEndpoint, httputil.AlertContactsField, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	alertContacts := make([]map[string]interface{}, 0, len(remoteAlertContacts))
+	for _, remoteAlertContact := range remoteAlertContacts {
+		alertContact := map[string]interface{}{
+			httputil.FriendlyNameField: remoteAlertContact[httputil.FriendlyNameField],
+			httputil.ValueField:        remoteAlertContact[httputil.ValueField],
+		}
+		if id, err := serviceutil.ToInt(remoteAlertContact[httputil.TypeField]); err == nil {
+			if name, exists := model.AlertContactEnums[httputil.TypeField].Name(id); exists {
+				alertContact[httputil.TypeField] = name
+			}
+		}
+		alertContacts = append(alertContacts, alertContact)
+	}
+	return alertContacts, nil
+}
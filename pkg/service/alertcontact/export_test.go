@@ -0,0 +1,29 @@
+package alertcontact
+
+import (
+	"context"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"reflect"
+	"testing"
+)
+
+func TestAlertContactService_HandleRequest_Export(t *testing.T) {
+	testalertcontactservice := &testAlertContactService{}
+	testalertcontactservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(alertContactsResponse, nil)
+	defer testalertcontactservice.AssertExpectations(t)
+	alertcontactservice := &AlertContactService{IService: testalertcontactservice}
+
+	got := alertcontactservice.HandleRequest(context.Background(), []map[string]interface{}{{}}, model.Export)
+	want := []map[string]interface{}{{
+		model.ErrorResultField:            nil,
+		model.AlertContactNameResultField: "",
+		model.DataResultField: []map[string]interface{}{
+			{httputil.FriendlyNameField: "ops@email.com", httputil.TypeField: "email", httputil.ValueField: "ops@email.com"},
+			{httputil.FriendlyNameField: "ops-slack", httputil.TypeField: "slack", httputil.ValueField: "https://hooks.slack.com/services/x"},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleRequest() = %v, want %v", got, want)
+	}
+}
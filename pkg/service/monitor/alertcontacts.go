@@ -0,0 +1,230 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	MonitorAlertContactsCacheEnv    = "MONITOR_ALERT_CONTACTS_CACHE"
+	MonitorAlertContactsCacheTTLEnv = "MONITOR_ALERT_CONTACTS_CACHE_TTL"
+)
+
+// DefaultAlertContactsCacheTTL is how long alert contacts cached on disk are used for.
+const DefaultAlertContactsCacheTTL = 10 * time.Minute
+
+const (
+	MsgAmbiguousAlertContact       = "more than one alert contact is named %s, use its id"
+	MsgAlertContactsCacheNotUsed   = "alert contacts cache %s not used: %v"
+	MsgAlertContactsCacheNotStored = "alert contacts cache %s not written: %v"
+)
+
+/*
+*
+alertContactIndex maps the friendly_names of the alert contacts in the account to their ids. It is loaded once by the
+first monitor that needs it and shared by the monitors of a request.
+*/
+type alertContactIndex struct {
+	mu     sync.Mutex
+	loaded bool
+	// cached is true when the index was loaded from AlertContactsCache, see resolveAlertContact.
+	cached bool
+	ids    map[string]string
+	// names maps the ids back to friendly_names.
+	names map[string]string
+	// duplicates are the friendly_names used by more than one alert contact.
+	duplicates map[string]bool
+}
+
+// alertContactsCache is the content of the on-disk cache, account is a hash of the API key the contacts belong to.
+type alertContactsCache struct {
+	Account       string               `json:"account"`
+	FetchedAt     time.Time            `json:"fetched_at"`
+	AlertContacts []cachedAlertContact `json:"alert_contacts"`
+}
+
+type cachedAlertContact struct {
+	Id           string `json:"id"`
+	FriendlyName string `json:"friendly_name"`
+}
+
+// Indexes alertContacts by friendly_name.
+func (index *alertContactIndex) load(alertContacts []cachedAlertContact) {
+	index.ids = make(map[string]string, len(alertContacts))
+	index.names = make(map[string]string, len(alertContacts))
+	index.duplicates = make(map[string]bool)
+	for _, alertContact := range alertContacts {
+		if id, exists := index.ids[alertContact.FriendlyName]; exists && id != alertContact.Id {
+			index.duplicates[alertContact.FriendlyName] = true
+		}
+		index.ids[alertContact.FriendlyName] = alertContact.Id
+		index.names[alertContact.Id] = alertContact.FriendlyName
+	}
+	index.loaded = true
+}
+
+/*
+*
+Returns the id of the alert contact named alertContactFriendlyName, an alert contact that is not named so but is numeric
+is taken to be an id already. An error is returned when the name is used by more than one alert contact.
+*/
+func (index *alertContactIndex) resolve(alertContactFriendlyName string) (string, error) {
+	if len(index.ids) == 0 {
+		return "", errors.New(MsgNoAlertContactsFound)
+	}
+	if index.duplicates[alertContactFriendlyName] {
+		return "", fmt.Errorf(MsgAmbiguousAlertContact, alertContactFriendlyName)
+	}
+	if id, exists := index.ids[alertContactFriendlyName]; exists {
+		return id, nil
+	}
+	if _, err := strconv.Atoi(alertContactFriendlyName); err != nil {
+		return "", fmt.Errorf(MsgAlertContactNotResolved, alertContactFriendlyName)
+	}
+	return alertContactFriendlyName, nil
+}
+
+// Returns the friendly_name of the alert contact with id.
+func (index *alertContactIndex) name(id string) (string, bool) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	name, exists := index.names[id]
+	return name, exists
+}
+
+/*
+*
+Returns the id of the alert contact named alertContactFriendlyName on index, see alertContactIndex.resolve. An index
+loaded from AlertContactsCache is fetched again once when the name is not resolved, since the alert contact may have been
+created after the cache was written.
+*/
+func (service *MonitorService) resolveAlertContact(index *alertContactIndex, alertContactFriendlyName string) (string, error) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	id, err := index.resolve(alertContactFriendlyName)
+	if err == nil || !index.cached {
+		return id, err
+	}
+
+	alertContacts, fetchErr := service.fetchRemoteAlertContacts()
+	if fetchErr != nil {
+		return "", fetchErr
+	}
+	index.load(alertContacts)
+	index.cached = false
+	return index.resolve(alertContactFriendlyName)
+}
+
+/*
+*
+Returns the alert contact index shared by the monitors of the request, loading it on first use. Outside a request a new
+index is loaded on every call.
+*/
+func (service *MonitorService) alertContactIndex() (*alertContactIndex, error) {
+	index := service.alertContacts
+	if index == nil {
+		index = &alertContactIndex{}
+	}
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	if index.loaded {
+		return index, nil
+	}
+
+	alertContacts, cached, err := service.fetchAlertContacts()
+	if err != nil {
+		return nil, err
+	}
+	index.load(alertContacts)
+	index.cached = cached
+	return index, nil
+}
+
+/*
+*
+Returns the id and friendly_name of every alert contact in the account and whether they were read from the cache. When
+AlertContactsCache is set they are read from that file as long as it is younger than AlertContactsCacheTTL and was
+written for the same API key, otherwise they are fetched and the file is rewritten.
+*/
+func (service *MonitorService) fetchAlertContacts() ([]cachedAlertContact, bool, error) {
+	if service.AlertContactsCache != "" {
+		cache, err := readAlertContactsCache(service.AlertContactsCache)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				service.logger().Warnf(MsgAlertContactsCacheNotUsed, service.AlertContactsCache, err)
+			}
+		} else if cache.Account == service.alertContactsAccount() && time.Since(cache.FetchedAt) < service.alertContactsCacheTTL() {
+			return cache.AlertContacts, true, nil
+		}
+	}
+	alertContacts, err := service.fetchRemoteAlertContacts()
+	return alertContacts, false, err
+}
+
+// Fetches the id and friendly_name of every alert contact from the API, AlertContactsCache is rewritten when it is set.
+func (service *MonitorService) fetchRemoteAlertContacts() ([]cachedAlertContact, error) {
+	remoteAlertContacts, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetAlertContactsEndpoint, httputil.AlertContactsField, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	alertContacts := make([]cachedAlertContact, 0, len(remoteAlertContacts))
+	for _, remoteAlertContact := range remoteAlertContacts {
+		if remoteAlertContact[httputil.IdField] == nil || remoteAlertContact[httputil.FriendlyNameField] == nil {
+			continue
+		}
+		alertContacts = append(alertContacts, cachedAlertContact{
+			Id:           serviceutil.ToString(remoteAlertContact[httputil.IdField]),
+			FriendlyName: fmt.Sprint(remoteAlertContact[httputil.FriendlyNameField]),
+		})
+	}
+
+	if service.AlertContactsCache != "" {
+		cache := alertContactsCache{Account: service.alertContactsAccount(), FetchedAt: time.Now(), AlertContacts: alertContacts}
+		if err := writeAlertContactsCache(service.AlertContactsCache, cache); err != nil {
+			service.logger().Warnf(MsgAlertContactsCacheNotStored, service.AlertContactsCache, err)
+		}
+	}
+	return alertContacts, nil
+}
+
+// Returns the hash of the API key the alert contacts belong to.
+func (service *MonitorService) alertContactsAccount() string {
+	apiKey, _ := httputil.ApiKey(service.IService)
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func (service *MonitorService) alertContactsCacheTTL() time.Duration {
+	if service.AlertContactsCacheTTL > 0 {
+		return service.AlertContactsCacheTTL
+	}
+	return DefaultAlertContactsCacheTTL
+}
+
+func readAlertContactsCache(path string) (alertContactsCache, error) {
+	var cache alertContactsCache
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache, err
+	}
+	err = json.Unmarshal(data, &cache)
+	return cache, err
+}
+
+func writeAlertContactsCache(path string, cache alertContactsCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	// the cache only holds ids and names but is written for the current user only as it is tied to the API key
+	return os.WriteFile(path, data, 0600)
+}
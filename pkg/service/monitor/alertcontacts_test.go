@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Returns a getAlertContacts page of total alert contacts named "contact <id>" starting at offset.
+func alertContactsPage(offset, limit, total int) map[string]interface{} {
+	var alertContacts []interface{}
+	for id := offset + 1; id <= offset+limit && id <= total; id++ {
+		alertContacts = append(alertContacts, map[string]interface{}{
+			httputil.IdField: float64(id), httputil.FriendlyNameField: fmt.Sprintf("contact %d", id),
+		})
+	}
+	return map[string]interface{}{
+		"stat": "ok", httputil.LimitField: float64(limit), httputil.TotalField: float64(total), httputil.AlertContactsField: alertContacts,
+	}
+}
+
+func TestMonitorService_HandleRequest_AlertContactsFetchedOnce(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("true", true)
+	testmonitorservice.On("LookUpEnv", MonitorAlertContactsAttribDelimiterEnv).Return("", false)
+	testmonitorservice.On("LookUpEnv", MonitorAlertContactsDelimiterEnv).Return("", false)
+	for offset := 0; offset < 120; offset += 50 {
+		testmonitorservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, map[string]interface{}{httputil.OffsetField: offset}).Return(alertContactsPage(offset, 50, 120), nil).Once()
+	}
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, Concurrency: 4}
+
+	var data []map[string]interface{}
+	for idx := 0; idx < 10; idx++ {
+		data = append(data, map[string]interface{}{
+			httputil.FriendlyNameField:  fmt.Sprintf("monitor-%d", idx),
+			httputil.UrlField:           "https://localhost",
+			httputil.TypeField:          "HTTP",
+			httputil.AlertContactsField: "contact 1_0_5-contact 120",
+		})
+	}
+	for _, result := range monitorservice.HandleRequest(context.Background(), data, model.Create) {
+		if result[model.ErrorResultField] != nil {
+			t.Fatalf("HandleRequest() error = %v", result[model.ErrorResultField])
+		}
+	}
+	for _, dataMap := range data {
+		if dataMap[httputil.AlertContactsField] != "1_0_5-120" {
+			t.Errorf("HandleRequest() alert_contacts = %v, want %v", dataMap[httputil.AlertContactsField], "1_0_5-120")
+		}
+	}
+	// 3 pages of 120 alert contacts for the whole batch, the last one was missed when the page count was truncated
+	testmonitorservice.AssertNumberOfCalls(t, "HttpInitiatePostRequest", 3+len(data))
+}
+
+func TestAlertContactIndex_resolve(t *testing.T) {
+	index := &alertContactIndex{}
+	index.load([]cachedAlertContact{
+		{Id: "1", FriendlyName: "ops"},
+		{Id: "2", FriendlyName: "on-call"},
+		{Id: "3", FriendlyName: "on-call"},
+	})
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "should resolve a friendly_name", value: "ops", want: "1"},
+		{name: "should keep ids", value: "3", want: "3"},
+		{name: "should return error when the friendly_name is used more than once", value: "on-call", wantErr: fmt.Errorf(MsgAmbiguousAlertContact, "on-call")},
+		{name: "should return error when the friendly_name does not exist", value: "dev", wantErr: fmt.Errorf(MsgAlertContactNotResolved, "dev")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := index.resolve(tt.value)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolve() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorService_fetchAlertContacts_Cache(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "alert-contacts.json")
+	newService := func(apiKey string, ttl time.Duration) (*MonitorService, *testMonitorService) {
+		testmonitorservice := &testMonitorService{}
+		testmonitorservice.On("LookUpEnv", httputil.UptimeRobotApiKeyEnv).Return(apiKey, true)
+		testmonitorservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(alertContactsPage(0, 50, 2), nil)
+		return &MonitorService{IService: testmonitorservice, AlertContactsCache: cachePath, AlertContactsCacheTTL: ttl}, testmonitorservice
+	}
+	want := []cachedAlertContact{{Id: "1", FriendlyName: "contact 1"}, {Id: "2", FriendlyName: "contact 2"}}
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		ttl       time.Duration
+		wantCalls int
+	}{
+		{name: "should fetch and write the cache when there is none", apiKey: "key", ttl: time.Hour, wantCalls: 1},
+		{name: "should read the cache while it is fresh", apiKey: "key", ttl: time.Hour, wantCalls: 0},
+		{name: "should fetch when the cache was written for another API key", apiKey: "other-key", ttl: time.Hour, wantCalls: 1},
+		{name: "should fetch when the cache is older than the TTL", apiKey: "other-key", ttl: time.Nanosecond, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitorservice, testmonitorservice := newService(tt.apiKey, tt.ttl)
+			got, _, err := monitorservice.fetchAlertContacts()
+			if err != nil {
+				t.Fatalf("fetchAlertContacts() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("fetchAlertContacts() got = %v, want %v", got, want)
+			}
+			testmonitorservice.AssertNumberOfCalls(t, "HttpInitiatePostRequest", tt.wantCalls)
+		})
+	}
+}
+
+func TestMonitorService_resolveAlertContact_StaleCache(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("LookUpEnv", httputil.UptimeRobotApiKeyEnv).Return("key", true)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(alertContactsPage(0, 50, 2), nil).Once()
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, AlertContactsCache: filepath.Join(t.TempDir(), "alert-contacts.json"), alertContacts: &alertContactIndex{}}
+	cache := alertContactsCache{Account: monitorservice.alertContactsAccount(), FetchedAt: time.Now(), AlertContacts: []cachedAlertContact{{Id: "1", FriendlyName: "contact 1"}}}
+	if err := writeAlertContactsCache(monitorservice.AlertContactsCache, cache); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "should resolve a cached alert contact without fetching", value: "contact 1", want: "1"},
+		{name: "should fetch once when an alert contact is not cached", value: "contact 2", want: "2"},
+		{name: "should not fetch again once fetched", value: "contact 3", wantErr: fmt.Errorf(MsgAlertContactNotResolved, "contact 3")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := monitorservice.resolveAlertContactByFriendlyName(tt.value)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("resolveAlertContactByFriendlyName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveAlertContactByFriendlyName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package monitor
+
+import (
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	"strconv"
+	"sync/atomic"
+)
+
+const (
+	MonitorApplyPruneEnv      = "MONITOR_APPLY_PRUNE"
+	MonitorApplyForcePruneEnv = "MONITOR_APPLY_FORCE_PRUNE"
+)
+
+const (
+	MsgDuplicateMonitor = "monitor %s is specified more than once"
+	MsgAmbiguousMonitor = "more than one monitor is named %s (ids %s), specify the id"
+	MsgPruneEverything  = "not pruning since no monitors are desired, which would delete every monitor in the account, set %s to do so"
+)
+
+// noMonitor is returned by planApply as the failed index when the error is not about any one desired monitor.
+const noMonitor = -1
+
+// applyStep is a change computed by apply for a single monitor.
+type applyStep struct {
+	action  string
+	name    interface{}
+	dataMap map[string]interface{}
+	remote  map[string]interface{}
+	changes []model.Change
+}
+
+/*
+*
+Reconciles the remote monitors with desiredMonitors, the full desired state:
+1. Fetches all remote monitors once and matches every desired monitor by id if present otherwise by exact friendly_name.
+2. Updates matched monitors (see updateMonitor) and creates the rest.
+3. Deletes remote monitors that are not desired when MONITOR_APPLY_PRUNE is true, as long as some monitors are desired
+or MONITOR_APPLY_FORCE_PRUNE is true too.
+All desired monitors are validated before anything is changed, an invalid monitor stops the run. A failed create or update
+stops the run too unless ContinueOnError is set, either way nothing is pruned. Monitors are pruned once all desired
+monitors have been created or updated.
+*/
+func (service *MonitorService) ApplyRequest(desiredMonitors []map[string]interface{}) []map[string]interface{} {
+	resultArrayMap := make([]map[string]interface{}, len(desiredMonitors))
+	for idx := range resultArrayMap {
+		resultArrayMap[idx] = make(map[string]interface{})
+	}
+
+	steps, failedIdx, err := service.planApply(desiredMonitors)
+	if err != nil && failedIdx == noMonitor {
+		// the desired monitors are left unhandled and the error is reported on its own
+		return append(resultArrayMap, map[string]interface{}{model.ErrorResultField: err})
+	} else if err != nil {
+		return createResultObject(model.Result{ErrorResultField: err, NameResultField: desiredMonitors[failedIdx][httputil.FriendlyNameField]}, failedIdx, resultArrayMap)
+	}
+
+	if !service.runApplySteps(steps[:len(desiredMonitors)], 0, resultArrayMap) {
+		return resultArrayMap
+	}
+	for range steps[len(desiredMonitors):] {
+		resultArrayMap = append(resultArrayMap, make(map[string]interface{}))
+	}
+	service.runApplySteps(steps[len(desiredMonitors):], len(desiredMonitors), resultArrayMap)
+	return resultArrayMap
+}
+
+/*
+*
+Performs steps concurrently (see MonitorService.Concurrency) populating their results on resultArrayMap from offset.
+Returns false when a step failed or the request was cancelled, in which case no further steps are started unless
+ContinueOnError is set.
+*/
+func (service *MonitorService) runApplySteps(steps []applyStep, offset int, resultArrayMap []map[string]interface{}) bool {
+	var failed int32
+	serviceutil.RunPool(service.requestContext(), service.Concurrency, len(steps), func(idx int) bool {
+		step := steps[idx]
+		var err error
+		var stepService *MonitorService
+		switch step.action {
+		case model.Created:
+			stepService = service.forItem(step.dataMap)
+			stepService.source = service.sourceOf(offset + idx)
+			err = stepService.CreateRequest(step.dataMap)
+		case model.Updated:
+			stepService = service.forItem(step.dataMap)
+			stepService.source = service.sourceOf(offset + idx)
+			err = stepService.updateMonitor(step.dataMap, step.remote)
+		case model.Deleted:
+			stepService = service.forItem(step.remote)
+			stepService.logger().Infof("pruning %v monitor", step.remote[httputil.FriendlyNameField])
+			err = stepService.deleteMonitor(step.remote)
+		}
+		step.changes = stepService.takeChanges()
+
+		createResultObject(model.Result{ErrorResultField: err, NameResultField: step.name}, offset+idx, resultArrayMap)
+		if err != nil {
+			atomic.StoreInt32(&failed, 1)
+			return service.ContinueOnError
+		}
+		resultArrayMap[offset+idx][model.ActionResultField] = step.action
+		if len(step.changes) > 0 {
+			resultArrayMap[offset+idx][model.PlanResultField] = step.changes
+		}
+		// the action actually taken e.g. recreated when the type changed
+		stepService.addAction(resultArrayMap[offset+idx])
+		return true
+	})
+	return failed == 0 && service.requestContext().Err() == nil
+}
+
+/*
+*
+Computes the steps apply performs, one for each desired monitor in order followed by the prune deletions. On error the
+index of the desired monitor that failed is returned, noMonitor when the error is not about one of them.
+*/
+func (service *MonitorService) planApply(desiredMonitors []map[string]interface{}) ([]applyStep, int, error) {
+	prune, err := service.applyPrunes(len(desiredMonitors))
+	if err != nil {
+		return nil, noMonitor, err
+	}
+
+	for idx, dataMap := range desiredMonitors {
+		if err := service.isValidPayload(dataMap, model.Update); err != nil {
+			return nil, idx, err
+		}
+	}
+
+	remoteMonitors, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetMonitorsEndpoint, httputil.MonitorsField, withMonitorDetails(map[string]interface{}{}))
+	if err != nil {
+		return nil, noMonitor, err
+	}
+
+	steps := make([]applyStep, 0, len(desiredMonitors))
+	matched := make(map[string]bool)
+	for idx, dataMap := range desiredMonitors {
+		remoteMonitor, err := matchRemoteMonitor(dataMap, remoteMonitors)
+		if err != nil {
+			return nil, idx, err
+		}
+
+		step := applyStep{action: model.Created, name: dataMap[httputil.FriendlyNameField], dataMap: dataMap}
+		if remoteMonitor != nil {
+			id := serviceutil.ToString(remoteMonitor[httputil.IdField])
+			if matched[id] {
+				return nil, idx, fmt.Errorf(MsgDuplicateMonitor, fmt.Sprint(remoteMonitor[httputil.FriendlyNameField]))
+			}
+			matched[id] = true
+			step.action = model.Updated
+			step.remote = remoteMonitor
+		} else if err := service.isValidPayload(dataMap, model.Create); err != nil {
+			return nil, idx, err
+		}
+
+		if err := service.resolveMonitorProperties(dataMap); err != nil {
+			return nil, idx, err
+		}
+		steps = append(steps, step)
+	}
+
+	if prune {
+		for _, remoteMonitor := range remoteMonitors {
+			if !matched[serviceutil.ToString(remoteMonitor[httputil.IdField])] {
+				steps = append(steps, applyStep{action: model.Deleted, name: remoteMonitor[httputil.FriendlyNameField], remote: remoteMonitor})
+			}
+		}
+	}
+	return steps, 0, nil
+}
+
+/*
+*
+Returns the remote monitor with the id of dataMap if present otherwise the one whose friendly_name matches exactly, nil
+when none matches.
+*/
+func matchRemoteMonitor(dataMap map[string]interface{}, remoteMonitors []map[string]interface{}) (map[string]interface{}, error) {
+	field := httputil.FriendlyNameField
+	if dataMap[httputil.IdField] != nil {
+		field = httputil.IdField
+	}
+
+	var candidates []map[string]interface{}
+	for _, remoteMonitor := range remoteMonitors {
+		if remoteMonitor[field] != nil && serviceutil.ToString(remoteMonitor[field]) == serviceutil.ToString(dataMap[field]) {
+			candidates = append(candidates, remoteMonitor)
+		}
+	}
+	if len(candidates) > 1 {
+		return nil, ambiguousMonitorError(serviceutil.ToString(dataMap[field]), candidates)
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return nil, nil
+}
+
+/*
+*
+Returns whether monitors that are not desired are pruned, see MONITOR_APPLY_PRUNE. Pruning with no desiredCount e.g. an
+empty input is refused unless MONITOR_APPLY_FORCE_PRUNE is true.
+*/
+func (service *MonitorService) applyPrunes(desiredCount int) (bool, error) {
+	val, found := service.IService.LookUpEnv(MonitorApplyPruneEnv)
+	if !found {
+		return false, nil
+	}
+	prune, err := strconv.ParseBool(val)
+	if err != nil || !prune || desiredCount > 0 {
+		return prune, err
+	}
+	val, found = service.IService.LookUpEnv(MonitorApplyForcePruneEnv)
+	if !found {
+		return false, fmt.Errorf(MsgPruneEverything, MonitorApplyForcePruneEnv)
+	}
+	force, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, err
+	}
+	if !force {
+		return false, fmt.Errorf(MsgPruneEverything, MonitorApplyForcePruneEnv)
+	}
+	return true, nil
+}
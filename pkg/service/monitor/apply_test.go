@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"reflect"
+	"testing"
+)
+
+func TestMonitorService_ApplyRequest(t *testing.T) {
+	var testmonitorservice *testMonitorService
+	monitorservice := &MonitorService{}
+
+	desiredMonitors := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost/health", httputil.TypeField: "HTTP"},
+			{httputil.FriendlyNameField: "web", httputil.UrlField: "https://web.localhost", httputil.TypeField: "HTTP"},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		args        []map[string]interface{}
+		setupMocks  func()
+		verifyMocks func()
+		want        []map[string]interface{}
+	}{
+		{name: "should update matched monitors and create the rest without pruning", args: desiredMonitors(), setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorApplyPruneEnv).Return("", false)
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.EditMonitorEndpoint, map[string]interface{}{
+				httputil.IdField:           float64(777712827),
+				httputil.FriendlyNameField: "api",
+				httputil.UrlField:          "https://api.localhost/health",
+				httputil.TypeField:         uint8(1),
+			}).Return(map[string]interface{}{}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, map[string]interface{}{
+				httputil.FriendlyNameField: "web",
+				httputil.UrlField:          "https://web.localhost",
+				httputil.TypeField:         uint8(1),
+			}).Return(map[string]interface{}{}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "api", model.ActionResultField: model.Updated, model.IdResultField: "777712827",
+				model.ChangedFieldsResultField: []string{httputil.UrlField}},
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "web", model.ActionResultField: model.Created},
+		}},
+		{name: "should not edit monitors that already match", args: []map[string]interface{}{
+			{httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost", httputil.TypeField: "HTTP", httputil.AlertContactsField: "2-1_0_5", httputil.MWindowsField: "10"},
+		}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorApplyPruneEnv).Return("", false)
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+			testmonitorservice.AssertNotCalled(t, "HttpInitiatePostRequest", httputil.EditMonitorEndpoint, mock.Anything)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "api", model.ActionResultField: model.Unchanged, model.IdResultField: "777712827"},
+		}},
+		{name: "should delete monitors missing from the desired state on prune", args: desiredMonitors(), setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorApplyPruneEnv).Return("true", true)
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.EditMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "777712828"}).Return(map[string]interface{}{}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "api", model.ActionResultField: model.Updated, model.IdResultField: "777712827",
+				model.ChangedFieldsResultField: []string{httputil.UrlField}},
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "web", model.ActionResultField: model.Created},
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "api-staging", model.ActionResultField: model.Deleted, model.IdResultField: "777712828"},
+		}},
+		{name: "should not change anything when a monitor is specified more than once", args: []map[string]interface{}{
+			{httputil.FriendlyNameField: "api"},
+			{httputil.IdField: float64(777712827)},
+		}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorApplyPruneEnv).Return("true", true)
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{},
+			{model.ErrorResultField: fmt.Errorf(MsgDuplicateMonitor, "api"), model.MonitorNameResultField: ""},
+		}},
+		{name: "should not change anything when a new monitor is invalid", args: []map[string]interface{}{
+			{httputil.FriendlyNameField: "web", httputil.TypeField: "HTTP"},
+		}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorApplyPruneEnv).Return("true", true)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: fmt.Errorf(MsgFieldMissing, httputil.UrlField), model.MonitorNameResultField: "web"},
+		}},
+		{name: "should return error when remote monitors cannot be fetched", args: desiredMonitors(), setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorApplyPruneEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(nil, errors.New("unknown error"))
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{},
+			{},
+			{model.ErrorResultField: errors.New("unknown error")},
+		}},
+		{name: "should not prune every monitor when none are desired", args: []map[string]interface{}{}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorApplyPruneEnv).Return("true", true)
+			testmonitorservice.On("LookUpEnv", MonitorApplyForcePruneEnv).Return("", false)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: fmt.Errorf(MsgPruneEverything, MonitorApplyForcePruneEnv)},
+		}},
+		{name: "should prune every monitor when none are desired and pruning is forced", args: []map[string]interface{}{}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorApplyPruneEnv).Return("true", true)
+			testmonitorservice.On("LookUpEnv", MonitorApplyForcePruneEnv).Return("true", true)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "777712827"}).Return(map[string]interface{}{}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "777712828"}).Return(map[string]interface{}{}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "api", model.ActionResultField: model.Deleted, model.IdResultField: "777712827"},
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "api-staging", model.ActionResultField: model.Deleted, model.IdResultField: "777712828"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if got := monitorservice.HandleRequest(context.Background(), tt.args, model.Apply); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HandleRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_matchRemoteMonitor(t *testing.T) {
+	remoteMonitors := []map[string]interface{}{
+		{httputil.IdField: float64(1), httputil.FriendlyNameField: "api"},
+		{httputil.IdField: float64(2), httputil.FriendlyNameField: "api-staging"},
+		{httputil.IdField: float64(3), httputil.FriendlyNameField: "api-staging"},
+	}
+	tests := []struct {
+		name    string
+		dataMap map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "should match by id over friendly_name", dataMap: map[string]interface{}{httputil.IdField: "2", httputil.FriendlyNameField: "api"}, want: remoteMonitors[1]},
+		{name: "should match friendly_name exactly", dataMap: map[string]interface{}{httputil.FriendlyNameField: "api"}, want: remoteMonitors[0]},
+		{name: "should return nil when nothing matches", dataMap: map[string]interface{}{httputil.FriendlyNameField: "ap"}, want: nil},
+		{name: "should fail when friendly_name is ambiguous", dataMap: map[string]interface{}{httputil.FriendlyNameField: "api-staging"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchRemoteMonitor(tt.dataMap, remoteMonitors)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("matchRemoteMonitor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchRemoteMonitor() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	"strings"
+)
+
+const (
+	MsgAlertContactNameNotExportable = "alert contact %s is exported by id since its friendly_name %q contains a delimiter or is numeric"
+	MsgMWindowNameNotExportable      = "maintenance window %s is exported by id since its friendly_name %q contains a delimiter or is numeric"
+)
+
+/*
+*
+Returns every monitor in the account in the input shape (see normaliseMonitor) without ids, so that it can be applied to
+this or another account. Alert contact ids are replaced by their friendly_name which requires
+MONITOR_RESOLVE_ALERT_CONTACTS_BY_FRIENDLY_NAME to be true on import, maintenance window ids by their friendly_name too
+(see resolveMWindows).
+*/
+func (service *MonitorService) ExportRequest() ([]map[string]interface{}, error) {
+	monitors, err := service.ListRequest(map[string]interface{}{}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	alertContactNames := make(map[string]string)
+	alertContacts, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetAlertContactsEndpoint, httputil.AlertContactsField, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	for _, alertContact := range alertContacts {
+		id := serviceutil.ToString(alertContact[httputil.IdField])
+		name := fmt.Sprint(alertContact[httputil.FriendlyNameField])
+		if !isResolvableName(name) {
+			service.logger().Warnf(MsgAlertContactNameNotExportable, id, name)
+			continue
+		}
+		alertContactNames[id] = name
+	}
+
+	mWindowNames, err := service.exportableMWindowNames(monitors)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, monitor := range monitors {
+		delete(monitor, httputil.IdField)
+		if alertContactsStr, ok := monitor[httputil.AlertContactsField].(string); ok {
+			alertContactsArr := strings.Split(alertContactsStr, AlertContactsDelimiter)
+			for index, value := range alertContactsArr {
+				attribs := strings.Split(value, AlertContactsAttribDelimiter)
+				if name, exists := alertContactNames[attribs[0]]; exists {
+					attribs[0] = name
+				}
+				alertContactsArr[index] = strings.Join(attribs, AlertContactsAttribDelimiter)
+			}
+			monitor[httputil.AlertContactsField] = strings.Join(alertContactsArr, AlertContactsDelimiter)
+		}
+		if mWindowsStr, ok := monitor[httputil.MWindowsField].(string); ok {
+			mWindowsArr := strings.Split(mWindowsStr, httputil.ListDelimiter)
+			for index, id := range mWindowsArr {
+				if name, exists := mWindowNames[id]; exists {
+					mWindowsArr[index] = name
+				}
+			}
+			monitor[httputil.MWindowsField] = strings.Join(mWindowsArr, httputil.ListDelimiter)
+		}
+	}
+	return monitors, nil
+}
+
+// Returns the friendly_names of the maintenance windows by id that resolve back to them, none when no monitor has any.
+func (service *MonitorService) exportableMWindowNames(monitors []map[string]interface{}) (map[string]string, error) {
+	exportableNames := make(map[string]string)
+	hasMWindows := false
+	for _, monitor := range monitors {
+		hasMWindows = hasMWindows || monitor[httputil.MWindowsField] != nil
+	}
+	if !hasMWindows {
+		return exportableNames, nil
+	}
+
+	names, err := service.fetchMWindowNames()
+	if err != nil {
+		return nil, err
+	}
+	for id, name := range names {
+		if !isResolvableName(name) {
+			service.logger().Warnf(MsgMWindowNameNotExportable, id, name)
+			continue
+		}
+		exportableNames[id] = name
+	}
+	return exportableNames, nil
+}
+
+// Names of alert contacts and maintenance windows containing the default delimiters or made of digits would not resolve
+// back to the same resource when read from a monitor.
+func isResolvableName(name string) bool {
+	if name == "" || strings.Contains(name, AlertContactsDelimiter) || strings.Contains(name, AlertContactsAttribDelimiter) {
+		return false
+	}
+	_, err := serviceutil.ToInt(name)
+	return err != nil
+}
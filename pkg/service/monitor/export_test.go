@@ -0,0 +1,65 @@
+package monitor
+
+import (
+	"context"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"reflect"
+	"testing"
+)
+
+func TestMonitorService_HandleRequest_Export(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{
+		httputil.OffsetField: 0,
+	})).Return(getMonitorsResponse, nil)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(map[string]interface{}{
+		httputil.AlertContactsField: []interface{}{
+			map[string]interface{}{httputil.IdField: "1", httputil.FriendlyNameField: "ops"},
+			map[string]interface{}{httputil.IdField: "2", httputil.FriendlyNameField: "on-call"},
+		},
+	}, nil)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(map[string]interface{}{
+		httputil.MWindowsField: []interface{}{
+			map[string]interface{}{httputil.IdField: float64(10), httputil.FriendlyNameField: "deploy"},
+		},
+	}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice}
+
+	got := monitorservice.HandleRequest(context.Background(), []map[string]interface{}{{}, {}}, model.Export)
+	want := []map[string]interface{}{{
+		model.ErrorResultField:       nil,
+		model.MonitorNameResultField: "",
+		model.DataResultField: []map[string]interface{}{
+			{
+				httputil.FriendlyNameField:  "api",
+				httputil.UrlField:           "https://api.localhost",
+				httputil.TypeField:          "HTTP",
+				httputil.IntervalField:      float64(300),
+				httputil.AlertContactsField: "ops_0_5-2",
+				httputil.MWindowsField:      "deploy",
+			},
+			{
+				httputil.FriendlyNameField:    "api-staging",
+				httputil.UrlField:             "https://staging.api.localhost",
+				httputil.TypeField:            "Keyword",
+				httputil.KeywordTypeField:     "not exists",
+				httputil.KeywordCaseTypeField: "case insensitive",
+				httputil.KeywordValueField:    "error",
+				httputil.IntervalField:        float64(60),
+			},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleRequest() = %v, want %v", got, want)
+	}
+}
+
+func Test_isResolvableName(t *testing.T) {
+	for name, want := range map[string]bool{"ops": true, "on-call": false, "on_call": false, "123": false, "": false} {
+		if got := isResolvableName(name); got != want {
+			t.Errorf("isResolvableName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
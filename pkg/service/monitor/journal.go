@@ -0,0 +1,239 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	MonitorJournalEnv  = "MONITOR_JOURNAL"
+	MonitorOperatorEnv = "MONITOR_OPERATOR"
+)
+
+const (
+	MsgJournalUnavailable = "journal %s could not be opened, not sending %s: %v"
+	MsgJournalNotWritten  = "%s of monitor %v was sent but could not be written to journal %s: %v"
+	MsgJournalLineInvalid = "line %d of journal %s is invalid: %v"
+	MsgJournalNotSet      = "no journal to read, set %s"
+)
+
+// JournalEntry is a line of the journal, written for every newMonitor, editMonitor and deleteMonitor request sent.
+type JournalEntry struct {
+	Time     time.Time `json:"time"`
+	Operator string    `json:"operator,omitempty"`
+	// Source is the input the monitor was read from e.g. monitors.json[2], empty for prunes and restores.
+	Source   string `json:"source,omitempty"`
+	Endpoint string `json:"endpoint"`
+	Id       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	// Before and After are the monitor in the shape newMonitor accepts, Before is empty on create and After on delete.
+	Before   map[string]interface{} `json:"before,omitempty"`
+	After    map[string]interface{} `json:"after,omitempty"`
+	Request  map[string]interface{} `json:"request"`
+	Response map[string]interface{} `json:"response,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// JournalQuery selects journal entries, fields left empty match every entry.
+type JournalQuery struct {
+	// Name matches the friendly_name of the monitor.
+	Name  string
+	Since time.Time
+	Until time.Time
+}
+
+// Matches returns whether entry is selected by query.
+func (query JournalQuery) Matches(entry JournalEntry) bool {
+	if query.Name != "" && entry.Name != query.Name {
+		return false
+	}
+	if !query.Since.IsZero() && entry.Time.Before(query.Since) {
+		return false
+	}
+	if !query.Until.IsZero() && entry.Time.After(query.Until) {
+		return false
+	}
+	return true
+}
+
+/*
+*
+journal appends entries to a JSONL file, see MonitorService.Journal. It is shared by the items of a request which may be
+handled concurrently.
+*/
+type journal struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Opens the journal file unless it is open already, the file is only readable by the current user.
+func (j *journal) open() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	j.file = file
+	return nil
+}
+
+func (j *journal) append(entry JournalEntry) error {
+	if err := j.open(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.file.Write(append(line, '\n'))
+	return err
+}
+
+// Returns the path of the journal file.
+func (j *journal) name() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.path
+}
+
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+/*
+*
+Sends data to endpoint, one of the write endpoints, and appends the request to the journal along with the monitor before
+and after it, with their secrets redacted (see redactutil.Map). before is the monitor as returned by getMonitors, nil on
+create. Nothing is sent when the journal cannot be opened so that every write is accounted for. Without a journal (see
+MonitorService.Journal) data is only sent.
+*/
+func (service *MonitorService) writeMonitor(endpoint string, data map[string]interface{}, before map[string]interface{}) (map[string]interface{}, error) {
+	if service.DryRun || service.journal == nil {
+		return service.InitiateRequest(endpoint, data)
+	}
+	if err := service.journal.open(); err != nil {
+		return nil, fmt.Errorf(MsgJournalUnavailable, service.journal.name(), endpoint, err)
+	}
+	resultMap, err := service.InitiateRequest(endpoint, data)
+	request := redactutil.Map(data)
+
+	entry := JournalEntry{
+		Time:     time.Now().UTC(),
+		Operator: service.Operator,
+		Source:   service.source,
+		Endpoint: endpoint,
+		Request:  request,
+		Response: redactutil.Map(resultMap),
+	}
+	if request[httputil.IdField] != nil {
+		entry.Id = serviceutil.ToString(request[httputil.IdField])
+	}
+	if before != nil {
+		entry.Before = journalState(before)
+		entry.Id = serviceutil.ToString(before[httputil.IdField])
+		entry.Name = fmt.Sprint(before[httputil.FriendlyNameField])
+	}
+	if endpoint != httputil.DeleteMonitorEndpoint {
+		entry.After = make(map[string]interface{})
+		for field, value := range entry.Before {
+			entry.After[field] = value
+		}
+		for field, value := range entry.Request {
+			entry.After[field] = serviceutil.ToString(value)
+		}
+		if id := monitorIdOf(resultMap); id != "" {
+			entry.Id = id
+			entry.After[httputil.IdField] = id
+		}
+	}
+	if request[httputil.FriendlyNameField] != nil {
+		entry.Name = fmt.Sprint(request[httputil.FriendlyNameField])
+	}
+	if err != nil {
+		entry.Error = redactutil.String(err.Error())
+	}
+	if journalErr := service.journal.append(entry); journalErr != nil {
+		service.logger().Errorf(MsgJournalNotWritten, endpoint, entry.Name, service.journal.name(), journalErr)
+	}
+	return resultMap, err
+}
+
+// Returns remoteMonitor, as returned by getMonitors, in the shape newMonitor accepts with its id and secrets redacted.
+func journalState(remoteMonitor map[string]interface{}) map[string]interface{} {
+	state := restorePayload(remoteMonitor)
+	if remoteMonitor[httputil.IdField] != nil {
+		state[httputil.IdField] = serviceutil.ToString(remoteMonitor[httputil.IdField])
+	}
+	return redactutil.Map(state)
+}
+
+/*
+*
+Returns the entries of the journal selected by query in the order they were written. A journal that does not exist yet
+has no entries.
+*/
+func (service *MonitorService) JournalRequest(query JournalQuery) ([]JournalEntry, error) {
+	path := service.Journal
+	if path == "" {
+		return nil, fmt.Errorf(MsgJournalNotSet, MonitorJournalEnv)
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []JournalEntry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make([]JournalEntry, 0)
+	scanner := bufio.NewScanner(file)
+	// request bodies can make for long lines
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf(MsgJournalLineInvalid, lineNumber, path, err)
+		}
+		if query.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Returns the name of the user running the tool, used as the operator when MONITOR_OPERATOR is not set.
+func currentOperator() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"github.com/stretchr/testify/mock"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonitorService_HandleRequest_Journal(t *testing.T) {
+	journalPath := filepath.Join(t.TempDir(), "journal.jsonl")
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+	testmonitorservice.On("LookUpEnv", MonitorResolveByFriendlyNameEnv).Return("", false)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0})).Return(map[string]interface{}{
+		httputil.MonitorsField: []interface{}{map[string]interface{}{
+			httputil.IdField: float64(1), httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost",
+			httputil.TypeField: float64(1), httputil.HttpPasswordField: "old-secret",
+		}},
+	}, nil)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.EditMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{httputil.MonitorField: map[string]interface{}{httputil.IdField: float64(1)}}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, Journal: journalPath, Operator: "ops", Sources: []string{"monitors.json[0]"}}
+
+	got := monitorservice.HandleRequest(context.Background(), []map[string]interface{}{{
+		httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost/health", httputil.HttpPasswordField: "new-secret",
+	}}, model.Update)
+	if got[0][model.ErrorResultField] != nil {
+		t.Fatalf("HandleRequest() error = %v", got[0][model.ErrorResultField])
+	}
+
+	data, err := os.ReadFile(journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("HandleRequest() journaled a secret: %s", data)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("HandleRequest() journaled %d entries, want 1", len(lines))
+	}
+	var entry JournalEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	entry.Time = time.Time{}
+	want := JournalEntry{
+		Operator: "ops",
+		Source:   "monitors.json[0]",
+		Endpoint: httputil.EditMonitorEndpoint,
+		Id:       "1",
+		Name:     "api",
+		Before: map[string]interface{}{
+			httputil.IdField: "1", httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost",
+			httputil.TypeField: "1", httputil.HttpPasswordField: redactutil.Redacted,
+		},
+		After: map[string]interface{}{
+			httputil.IdField: "1", httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost/health",
+			httputil.TypeField: "1", httputil.HttpPasswordField: redactutil.Redacted,
+		},
+		Request: map[string]interface{}{
+			httputil.IdField: float64(1), httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost/health",
+			httputil.HttpPasswordField: redactutil.Redacted,
+		},
+		Response: map[string]interface{}{httputil.MonitorField: map[string]interface{}{httputil.IdField: float64(1)}},
+	}
+	if !reflect.DeepEqual(entry, want) {
+		t.Errorf("HandleRequest() journaled %+v, want %+v", entry, want)
+	}
+}
+
+func TestMonitorService_writeMonitor_JournalUnavailable(t *testing.T) {
+	// a file where the directory of the journal should be
+	parent := filepath.Join(t.TempDir(), "journal")
+	if err := os.WriteFile(parent, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	testmonitorservice := &testMonitorService{}
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, journal: &journal{path: filepath.Join(parent, "journal.jsonl")}}
+
+	if _, err := monitorservice.writeMonitor(httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "1"}, nil); err == nil {
+		t.Errorf("writeMonitor() error = nil, want an error")
+	}
+	testmonitorservice.AssertNotCalled(t, "HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, mock.Anything)
+}
+
+func TestMonitorService_writeMonitor_NoJournal(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "1"}).Return(map[string]interface{}{}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice}
+
+	if got := monitorservice.HandleRequest(context.Background(), nil, model.Delete); len(got) != 0 {
+		t.Fatalf("HandleRequest() = %v, want no results", got)
+	}
+	if monitorservice.journal != nil {
+		t.Fatalf("HandleRequest() opened a journal without %s", MonitorJournalEnv)
+	}
+	if _, err := monitorservice.writeMonitor(httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "1"}, nil); err != nil {
+		t.Errorf("writeMonitor() error = %v", err)
+	}
+}
+
+func TestMonitorService_JournalRequest_NoJournal(t *testing.T) {
+	want := fmt.Sprintf(MsgJournalNotSet, MonitorJournalEnv)
+	if _, err := (&MonitorService{}).JournalRequest(JournalQuery{}); err == nil || err.Error() != want {
+		t.Errorf("JournalRequest() error = %v, want %s", err, want)
+	}
+}
+
+func TestMonitorService_JournalRequest(t *testing.T) {
+	journalPath := filepath.Join(t.TempDir(), "journal.jsonl")
+	at := func(day int) time.Time {
+		return time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+	}
+	entries := []JournalEntry{
+		{Time: at(1), Endpoint: httputil.NewMonitorEndpoint, Id: "1", Name: "api"},
+		{Time: at(2), Endpoint: httputil.NewMonitorEndpoint, Id: "2", Name: "web"},
+		{Time: at(3), Endpoint: httputil.EditMonitorEndpoint, Id: "1", Name: "api"},
+	}
+	var lines []string
+	for _, entry := range entries {
+		line, _ := json.Marshal(entry)
+		lines = append(lines, string(line))
+	}
+	if err := os.WriteFile(journalPath, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	monitorservice := &MonitorService{Journal: journalPath}
+
+	tests := []struct {
+		name    string
+		query   JournalQuery
+		wantIds []string
+	}{
+		{name: "should return every entry", query: JournalQuery{}, wantIds: []string{"1", "2", "1"}},
+		{name: "should return the entries of a monitor", query: JournalQuery{Name: "api"}, wantIds: []string{"1", "1"}},
+		{name: "should return the entries in a time range", query: JournalQuery{Since: at(2), Until: at(3)}, wantIds: []string{"2", "1"}},
+		{name: "should return no entries when none match", query: JournalQuery{Name: "api", Since: at(4)}, wantIds: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := monitorservice.JournalRequest(tt.query)
+			if err != nil {
+				t.Fatalf("JournalRequest() error = %v", err)
+			}
+			gotIds := make([]string, len(got))
+			for idx, entry := range got {
+				gotIds[idx] = entry.Id
+			}
+			if !reflect.DeepEqual(gotIds, tt.wantIds) {
+				t.Errorf("JournalRequest() = %v, want %v", gotIds, tt.wantIds)
+			}
+		})
+	}
+
+	if err := os.WriteFile(journalPath, []byte(lines[0]+"\n{\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := monitorservice.JournalRequest(JournalQuery{}); err == nil || !strings.HasPrefix(err.Error(), fmt.Sprintf("line 2 of journal %s", journalPath)) {
+		t.Errorf("JournalRequest() error = %v, want line 2 to be invalid", err)
+	}
+}
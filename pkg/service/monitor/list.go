@@ -0,0 +1,200 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	"strings"
+)
+
+// MonitorShowSecretsEnv makes get and list print the http_username, http_password and credential headers of monitors.
+const MonitorShowSecretsEnv = "MONITOR_SHOW_SECRETS"
+
+// Fields get and list mask unless ShowSecrets is set, export keeps them so that its output can be applied.
+var credentialFields = []string{httputil.HttpUsernameField, httputil.HttpPasswordField}
+
+// Fields of a remote monitor that are kept when it is converted back to the input shape.
+var inputFields = []string{
+	httputil.IdField,
+	httputil.FriendlyNameField,
+	httputil.UrlField,
+	httputil.TypeField,
+	httputil.SubTypeField,
+	httputil.PortField,
+	httputil.KeywordTypeField,
+	httputil.KeywordCaseTypeField,
+	httputil.KeywordValueField,
+	httputil.IntervalField,
+	httputil.TimeoutField,
+	httputil.HttpUsernameField,
+	httputil.HttpPasswordField,
+	httputil.HttpAuthTypeField,
+	httputil.HttpMethodField,
+	httputil.PostTypeField,
+	httputil.PostValueField,
+	httputil.PostContentTypeField,
+	httputil.AlertContactsField,
+	httputil.MWindowsField,
+	httputil.CustomHttpHeadersField,
+	httputil.CustomHttpStatusesField,
+}
+
+/*
+*
+Returns the remote monitors matching filter in the input shape (see normaliseMonitor).
+On get: id or friendly_name needs to be present and friendly_name has to match exactly, an error is returned when no
+monitor matches.
+On list: id, friendly_name (matched like the API search i.e. on part of the friendly_name or url), type and status are
+optional filters.
+*/
+func (service *MonitorService) ListRequest(filter map[string]interface{}, exact bool) ([]map[string]interface{}, error) {
+	requestBody := withMonitorDetails(map[string]interface{}{})
+
+	if exact && filter[httputil.IdField] == nil && filter[httputil.FriendlyNameField] == nil {
+		return nil, fmt.Errorf(MsgFieldMissing, "id or friendly_name")
+	}
+	if filter[httputil.IdField] != nil {
+		requestBody[httputil.MonitorsField] = serviceutil.ToString(filter[httputil.IdField])
+	}
+	if filter[httputil.FriendlyNameField] != nil {
+		requestBody[httputil.SearchField] = fmt.Sprint(filter[httputil.FriendlyNameField])
+	}
+	if filter[httputil.TypeField] != nil {
+		typeId, resolved := model.MonitorEnums[httputil.TypeField].Resolve(filter[httputil.TypeField])
+		if !resolved {
+			return nil, fmt.Errorf(MsgMappingNotFound, httputil.TypeField, fmt.Sprint(filter[httputil.TypeField]))
+		}
+		requestBody[httputil.TypesField] = uint8(typeId)
+	}
+	if filter[httputil.StatusField] != nil {
+		status, resolved := model.MonitorStatuses.Resolve(filter[httputil.StatusField])
+		if !resolved {
+			return nil, fmt.Errorf(MsgMappingNotFound, httputil.StatusField, fmt.Sprint(filter[httputil.StatusField]))
+		}
+		requestBody[httputil.StatusesField] = uint8(status)
+	}
+
+	remoteMonitors, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetMonitorsEndpoint, httputil.MonitorsField, requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	monitors := make([]map[string]interface{}, 0, len(remoteMonitors))
+	for _, remoteMonitor := range remoteMonitors {
+		if exact && filter[httputil.FriendlyNameField] != nil && fmt.Sprint(remoteMonitor[httputil.FriendlyNameField]) != fmt.Sprint(filter[httputil.FriendlyNameField]) {
+			continue
+		}
+		monitors = append(monitors, normaliseMonitor(remoteMonitor))
+	}
+
+	if exact && len(monitors) == 0 {
+		return nil, errors.New(MsgMonitorDoesNotExist)
+	}
+	return monitors, nil
+}
+
+/*
+*
+Replaces the credentials of monitors, including the values of the credential headers in custom_http_headers (see
+redactutil.IsSecretHeader), with redactutil.Redacted unless ShowSecrets is set.
+*/
+func (service *MonitorService) maskCredentials(monitors []map[string]interface{}) {
+	if service.ShowSecrets {
+		return
+	}
+	for _, monitor := range monitors {
+		for _, field := range credentialFields {
+			if monitor[field] != nil {
+				monitor[field] = redactutil.Redacted
+			}
+		}
+		if monitor[httputil.CustomHttpHeadersField] != nil {
+			monitor[httputil.CustomHttpHeadersField] = redactutil.Field(httputil.CustomHttpHeadersField, monitor[httputil.CustomHttpHeadersField])
+		}
+	}
+}
+
+/*
+*
+Converts a monitor returned by getMonitors into the shape accepted as input i.e. only fields that can be created/updated
+are kept, numeric properties are mapped back to their names (see model.MonitorEnums), alert contacts are joined as
+id_threshold_recurrence and maintenance windows as ids, both separated by the default delimiters. Custom http headers and
+statuses are encoded as they are sent, see httputil.EncodeField.
+*/
+func normaliseMonitor(remoteMonitor map[string]interface{}) map[string]interface{} {
+	monitor := make(map[string]interface{})
+	for _, field := range inputFields {
+		value, exists := remoteMonitor[field]
+		if !exists || value == nil || value == "" {
+			continue
+		}
+
+		switch field {
+		case httputil.AlertContactsField, httputil.MWindowsField:
+			if formatted := formatRemoteList(field, value); formatted != "" {
+				monitor[field] = formatted
+			}
+		case httputil.CustomHttpHeadersField, httputil.CustomHttpStatusesField:
+			if encoded, err := httputil.EncodeField(field, value); err == nil && encoded != "" && encoded != "{}" {
+				monitor[field] = encoded
+			}
+		default:
+			if enum, exists := model.MonitorEnums[field]; exists {
+				id, err := serviceutil.ToInt(value)
+				if err != nil {
+					continue
+				}
+				name, exists := enum.Name(id)
+				if !exists {
+					continue
+				}
+				monitor[field] = name
+			} else {
+				monitor[field] = value
+			}
+		}
+	}
+
+	// sub_type and keyword properties are only meaningful for port and keyword monitors
+	if monitor[httputil.TypeField] != model.MonitorTypePort.String() {
+		delete(monitor, httputil.SubTypeField)
+		delete(monitor, httputil.PortField)
+	}
+	if monitor[httputil.TypeField] != model.MonitorTypeKeyword.String() {
+		delete(monitor, httputil.KeywordTypeField)
+		delete(monitor, httputil.KeywordCaseTypeField)
+		delete(monitor, httputil.KeywordValueField)
+	}
+	return monitor
+}
+
+/*
+*
+Joins the alert contacts (as id_threshold_recurrence) or maintenance windows (as ids) of a remote monitor using the
+default delimiters, returns an empty string when there are none.
+*/
+func formatRemoteList(field string, value interface{}) string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return ""
+	}
+	formatted := make([]string, 0, len(items))
+	for _, _item := range items {
+		item, ok := _item.(map[string]interface{})
+		if !ok || item[httputil.IdField] == nil {
+			continue
+		}
+		attribs := []string{serviceutil.ToString(item[httputil.IdField])}
+		if field == httputil.AlertContactsField && (item[httputil.ThresholdField] != nil || item[httputil.RecurrenceField] != nil) {
+			attribs = append(attribs, serviceutil.ToString(item[httputil.ThresholdField]), serviceutil.ToString(item[httputil.RecurrenceField]))
+		}
+		formatted = append(formatted, strings.Join(attribs, AlertContactsAttribDelimiter))
+	}
+	if field == httputil.AlertContactsField {
+		return strings.Join(formatted, AlertContactsDelimiter)
+	}
+	return strings.Join(formatted, "-")
+}
@@ -0,0 +1,239 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"github.com/stretchr/testify/mock"
+	"reflect"
+	"testing"
+)
+
+var getMonitorsResponse = map[string]interface{}{
+	"stat": "ok",
+	"pagination": map[string]interface{}{
+		"offset": float64(0),
+		"limit":  float64(50),
+		"total":  float64(2),
+	},
+	"monitors": []interface{}{
+		map[string]interface{}{
+			"id":                float64(777712827),
+			"friendly_name":     "api",
+			"url":               "https://api.localhost",
+			"type":              float64(1),
+			"sub_type":          "",
+			"keyword_type":      nil,
+			"keyword_case_type": nil,
+			"keyword_value":     "",
+			"port":              "",
+			"interval":          float64(300),
+			"status":            float64(2),
+			"create_datetime":   float64(1462565497),
+			"alert_contacts": []interface{}{
+				map[string]interface{}{"id": "1", "type": float64(2), "threshold": float64(0), "recurrence": float64(5)},
+				map[string]interface{}{"id": "2", "type": float64(11)},
+			},
+			"mwindows": []interface{}{
+				map[string]interface{}{"id": float64(10)},
+			},
+		},
+		map[string]interface{}{
+			"id":                float64(777712828),
+			"friendly_name":     "api-staging",
+			"url":               "https://staging.api.localhost",
+			"type":              float64(2),
+			"keyword_type":      float64(2),
+			"keyword_case_type": float64(1),
+			"keyword_value":     "error",
+			"interval":          float64(60),
+		},
+	},
+}
+
+func TestMonitorService_ListRequest(t *testing.T) {
+	var testmonitorservice *testMonitorService
+	monitorservice := &MonitorService{}
+
+	type args struct {
+		filter map[string]interface{}
+		exact  bool
+	}
+	tests := []struct {
+		name        string
+		args        args
+		setupMocks  func()
+		verifyMocks func()
+		want        []map[string]interface{}
+		wantErr     bool
+	}{
+		{name: "should list all monitors in the input shape", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{
+				httputil.OffsetField: 0,
+			})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{filter: map[string]interface{}{}, exact: false}, want: []map[string]interface{}{
+			{
+				httputil.IdField:            float64(777712827),
+				httputil.FriendlyNameField:  "api",
+				httputil.UrlField:           "https://api.localhost",
+				httputil.TypeField:          "HTTP",
+				httputil.IntervalField:      float64(300),
+				httputil.AlertContactsField: "1_0_5-2",
+				httputil.MWindowsField:      "10",
+			},
+			{
+				httputil.IdField:              float64(777712828),
+				httputil.FriendlyNameField:    "api-staging",
+				httputil.UrlField:             "https://staging.api.localhost",
+				httputil.TypeField:            "Keyword",
+				httputil.KeywordTypeField:     "not exists",
+				httputil.KeywordCaseTypeField: "case insensitive",
+				httputil.KeywordValueField:    "error",
+				httputil.IntervalField:        float64(60),
+			},
+		}, wantErr: false},
+		{name: "should filter by type and status", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{
+				httputil.TypesField:    uint8(2),
+				httputil.StatusesField: uint8(9),
+				httputil.OffsetField:   0,
+			})).Return(map[string]interface{}{httputil.MonitorsField: []interface{}{}}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{filter: map[string]interface{}{
+			httputil.TypeField:   "Keyword",
+			httputil.StatusField: "Down",
+		}, exact: false}, want: []map[string]interface{}{}, wantErr: false},
+		{name: "should return only the exact friendly_name match on get", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{filter: map[string]interface{}{
+			httputil.FriendlyNameField: "api-staging",
+		}, exact: true}, want: []map[string]interface{}{
+			{
+				httputil.IdField:              float64(777712828),
+				httputil.FriendlyNameField:    "api-staging",
+				httputil.UrlField:             "https://staging.api.localhost",
+				httputil.TypeField:            "Keyword",
+				httputil.KeywordTypeField:     "not exists",
+				httputil.KeywordCaseTypeField: "case insensitive",
+				httputil.KeywordValueField:    "error",
+				httputil.IntervalField:        float64(60),
+			},
+		}, wantErr: false},
+		{name: "should keep custom http headers and statuses", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+				httputil.MonitorsField: []interface{}{map[string]interface{}{
+					httputil.IdField: float64(1), httputil.FriendlyNameField: "web", httputil.TypeField: float64(1),
+					httputil.CustomHttpHeadersField:  map[string]interface{}{"Authorization": "Bearer token"},
+					httputil.CustomHttpStatusesField: "404:1",
+				}, map[string]interface{}{
+					httputil.IdField: float64(2), httputil.FriendlyNameField: "web-2", httputil.TypeField: float64(1),
+					httputil.CustomHttpHeadersField: map[string]interface{}{},
+				}},
+			}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{filter: map[string]interface{}{}, exact: false}, want: []map[string]interface{}{
+			{
+				httputil.IdField: float64(1), httputil.FriendlyNameField: "web", httputil.TypeField: "HTTP",
+				httputil.CustomHttpHeadersField:  `{"Authorization":"Bearer token"}`,
+				httputil.CustomHttpStatusesField: "404:1",
+			},
+			{httputil.IdField: float64(2), httputil.FriendlyNameField: "web-2", httputil.TypeField: "HTTP"},
+		}, wantErr: false},
+		{name: "should return error on get when no monitor matches", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{filter: map[string]interface{}{
+			httputil.FriendlyNameField: "ap",
+		}, exact: true}, want: nil, wantErr: true},
+		{name: "should return error on get without id or friendly_name", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{filter: map[string]interface{}{}, exact: true}, want: nil, wantErr: true},
+		{name: "should return error when type filter cannot be mapped", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{filter: map[string]interface{}{
+			httputil.TypeField: "gRPC",
+		}, exact: false}, want: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			got, err := monitorservice.ListRequest(tt.args.filter, tt.args.exact)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ListRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListRequest() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorService_HandleRequest_Get(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(nil, errors.New("unknown error"))
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice}
+
+	got := monitorservice.HandleRequest(context.Background(), []map[string]interface{}{{httputil.FriendlyNameField: "api"}}, model.Get)
+	want := []map[string]interface{}{{model.ErrorResultField: errors.New("unknown error"), model.MonitorNameResultField: "api"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleRequest() = %v, want %v", got, want)
+	}
+}
+
+func TestMonitorService_maskCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		showSecrets bool
+		want        map[string]interface{}
+	}{
+		{name: "should mask the credentials", want: map[string]interface{}{
+			httputil.FriendlyNameField: "api", httputil.HttpUsernameField: redactutil.Redacted, httputil.HttpPasswordField: redactutil.Redacted,
+			httputil.CustomHttpHeadersField: `{"Accept":"text/html","Authorization":"[REDACTED]"}`,
+		}},
+		{name: "should keep the credentials when secrets are shown", showSecrets: true, want: map[string]interface{}{
+			httputil.FriendlyNameField: "api", httputil.HttpUsernameField: "admin", httputil.HttpPasswordField: "password",
+			httputil.CustomHttpHeadersField: `{"Accept":"text/html","Authorization":"Bearer token"}`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitors := []map[string]interface{}{{
+				httputil.FriendlyNameField: "api", httputil.HttpUsernameField: "admin", httputil.HttpPasswordField: "password",
+				httputil.CustomHttpHeadersField: `{"Accept":"text/html","Authorization":"Bearer token"}`,
+			}}
+			monitorservice := &MonitorService{ShowSecrets: tt.showSecrets}
+			monitorservice.maskCredentials(monitors)
+			if !reflect.DeepEqual(monitors[0], tt.want) {
+				t.Errorf("maskCredentials() = %v, want %v", monitors[0], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	"strconv"
+	"strings"
+)
+
+const MonitorFuzzyMatchEnv = "MONITOR_FUZZY_MATCH"
+
+const MsgFuzzyMatchedMonitor = "%s matched monitor %s (id %s)"
+
+/*
+*
+Returns the remote monitor whose friendly_name is exactly (case-sensitively) name, nil when there is none. Every page of
+the getMonitors search is checked since the search also matches on part of the friendly_name or url. With fuzzy a monitor
+whose friendly_name matches ignoring case, or failing that contains name, is returned when none matches exactly, only
+update uses it (see FuzzyMatch) as deleting or referencing the wrong monitor cannot be undone. An error listing the
+candidate ids is returned when more than one monitor matches. With details the monitor includes the fields compared by
+diffMonitor.
+*/
+func (service *MonitorService) findMonitorByFriendlyName(name string, details bool, fuzzy bool) (map[string]interface{}, error) {
+	body := map[string]interface{}{httputil.SearchField: name}
+	if details {
+		body = withMonitorDetails(body)
+	}
+	remoteMonitors, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetMonitorsEndpoint, httputil.MonitorsField, body)
+	if err != nil {
+		return nil, err
+	}
+
+	matchers := []func(friendlyName string) bool{
+		func(friendlyName string) bool { return friendlyName == name },
+	}
+	if fuzzy {
+		matchers = append(matchers,
+			func(friendlyName string) bool { return strings.EqualFold(friendlyName, name) },
+			func(friendlyName string) bool {
+				return strings.Contains(strings.ToLower(friendlyName), strings.ToLower(name))
+			},
+		)
+	}
+	for idx, matches := range matchers {
+		var candidates []map[string]interface{}
+		for _, remoteMonitor := range remoteMonitors {
+			if remoteMonitor[httputil.FriendlyNameField] != nil && matches(fmt.Sprint(remoteMonitor[httputil.FriendlyNameField])) {
+				candidates = append(candidates, remoteMonitor)
+			}
+		}
+		if len(candidates) > 1 {
+			return nil, ambiguousMonitorError(name, candidates)
+		}
+		if len(candidates) == 1 {
+			if idx > 0 {
+				service.logger().Infof(MsgFuzzyMatchedMonitor, name, candidates[0][httputil.FriendlyNameField], serviceutil.ToString(candidates[0][httputil.IdField]))
+			}
+			return candidates[0], nil
+		}
+	}
+	return nil, nil
+}
+
+/*
+*
+Returns the ids of the monitors whose friendly_names are names, in the same order. The monitors are fetched once and each
+name is matched exactly (case-sensitively), numeric names that do not match a monitor friendly_name are assumed to already
+be an id. An error listing the candidate ids is returned when more than one monitor matches a name.
+*/
+func (service *MonitorService) ResolveMonitorIdsByFriendlyName(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	remoteMonitors, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetMonitorsEndpoint, httputil.MonitorsField, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string][]map[string]interface{}{}
+	for _, remoteMonitor := range remoteMonitors {
+		if remoteMonitor[httputil.FriendlyNameField] != nil {
+			friendlyName := fmt.Sprint(remoteMonitor[httputil.FriendlyNameField])
+			byName[friendlyName] = append(byName[friendlyName], remoteMonitor)
+		}
+	}
+
+	ids := make([]string, len(names))
+	for index, name := range names {
+		candidates := byName[name]
+		if len(candidates) > 1 {
+			return nil, ambiguousMonitorError(name, candidates)
+		}
+		if len(candidates) == 1 && candidates[0][httputil.IdField] != nil {
+			ids[index] = serviceutil.ToString(candidates[0][httputil.IdField])
+			continue
+		}
+		if _, err := strconv.Atoi(name); err != nil {
+			return nil, fmt.Errorf(MsgMonitorNotResolved, name)
+		}
+		ids[index] = name
+	}
+	return ids, nil
+}
+
+// Sets the context requests are made with, for services used outside HandleRequest.
+func (service *MonitorService) WithContext(ctx context.Context) *MonitorService {
+	service.ctx = ctx
+	return service
+}
+
+func ambiguousMonitorError(name string, candidates []map[string]interface{}) error {
+	return fmt.Errorf(MsgAmbiguousMonitor, name, serviceutil.JoinIds(candidates))
+}
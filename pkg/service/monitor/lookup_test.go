@@ -0,0 +1,130 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"reflect"
+	"testing"
+)
+
+func TestMonitorService_findMonitorByFriendlyName(t *testing.T) {
+	monitorsPage := func(offset int, monitors ...map[string]interface{}) map[string]interface{} {
+		list := make([]interface{}, len(monitors))
+		for idx, monitor := range monitors {
+			list[idx] = monitor
+		}
+		return map[string]interface{}{
+			httputil.PaginationField: map[string]interface{}{"offset": float64(offset), "limit": float64(2), "total": float64(3)},
+			httputil.MonitorsField:   list,
+		}
+	}
+	api := map[string]interface{}{httputil.IdField: float64(1), httputil.FriendlyNameField: "api"}
+	apiStaging := map[string]interface{}{httputil.IdField: float64(2), httputil.FriendlyNameField: "api-staging"}
+	apiUpper := map[string]interface{}{httputil.IdField: float64(3), httputil.FriendlyNameField: "API"}
+	apiDuplicate := map[string]interface{}{httputil.IdField: float64(4), httputil.FriendlyNameField: "api"}
+
+	tests := []struct {
+		name       string
+		search     string
+		fuzzyMatch bool
+		pages      []map[string]interface{}
+		want       map[string]interface{}
+		wantErr    error
+	}{
+		{name: "should match the friendly_name exactly across pages", search: "api", pages: []map[string]interface{}{
+			monitorsPage(0, apiStaging, apiUpper), monitorsPage(2, api),
+		}, want: api},
+		{name: "should not match part of a friendly_name", search: "api", pages: []map[string]interface{}{
+			monitorsPage(0, apiStaging, apiUpper), monitorsPage(2),
+		}, want: nil},
+		{name: "should return error listing the ids when more than one monitor matches", search: "api", pages: []map[string]interface{}{
+			monitorsPage(0, api, apiStaging), monitorsPage(2, apiDuplicate),
+		}, wantErr: fmt.Errorf(MsgAmbiguousMonitor, "api", "1, 4")},
+		{name: "should match ignoring case when fuzzy", search: "Api", fuzzyMatch: true, pages: []map[string]interface{}{
+			monitorsPage(0, apiStaging, apiUpper), monitorsPage(2),
+		}, want: apiUpper},
+		{name: "should match part of a friendly_name when fuzzy", search: "staging", fuzzyMatch: true, pages: []map[string]interface{}{
+			monitorsPage(0, api, apiStaging), monitorsPage(2),
+		}, want: apiStaging},
+		{name: "should return error when fuzzy matches are ambiguous", search: "ap", fuzzyMatch: true, pages: []map[string]interface{}{
+			monitorsPage(0, api, apiStaging), monitorsPage(2),
+		}, wantErr: fmt.Errorf(MsgAmbiguousMonitor, "ap", "1, 2")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testmonitorservice := &testMonitorService{}
+			for idx, page := range tt.pages {
+				testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, map[string]interface{}{
+					httputil.SearchField: tt.search, httputil.OffsetField: idx * 2,
+				}).Return(page, nil)
+			}
+			defer testmonitorservice.AssertExpectations(t)
+			monitorservice := &MonitorService{IService: testmonitorservice}
+
+			got, err := monitorservice.findMonitorByFriendlyName(tt.search, false, tt.fuzzyMatch)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("findMonitorByFriendlyName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findMonitorByFriendlyName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorService_DeleteRequest_NotFuzzy(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("LookUpEnv", MonitorResolveByFriendlyNameEnv).Return("true", true)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+		"stat": "ok", httputil.MonitorsField: []interface{}{
+			map[string]interface{}{httputil.IdField: float64(777712828), httputil.FriendlyNameField: "api-staging"},
+		},
+	}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, FuzzyMatch: true}
+
+	err := monitorservice.DeleteRequest(map[string]interface{}{httputil.FriendlyNameField: "api"})
+	if !reflect.DeepEqual(err, errors.New(MsgMonitorDoesNotExist)) {
+		t.Errorf("DeleteRequest() error = %v, wantErr %v", err, MsgMonitorDoesNotExist)
+	}
+}
+
+func TestMonitorService_ResolveMonitorIdsByFriendlyName(t *testing.T) {
+	monitors := map[string]interface{}{
+		"stat": "ok", httputil.MonitorsField: []interface{}{
+			map[string]interface{}{httputil.IdField: float64(1), httputil.FriendlyNameField: "api"},
+			map[string]interface{}{httputil.IdField: float64(2), httputil.FriendlyNameField: "api-staging"},
+			map[string]interface{}{httputil.IdField: float64(3), httputil.FriendlyNameField: "web"},
+			map[string]interface{}{httputil.IdField: float64(4), httputil.FriendlyNameField: "web"},
+		},
+	}
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr error
+	}{
+		{name: "should resolve every name from one fetch", names: []string{"api-staging", "api"}, want: []string{"2", "1"}},
+		{name: "should keep numeric names that match no monitor as ids", names: []string{"api", "12"}, want: []string{"1", "12"}},
+		{name: "should return error when a name cannot be resolved", names: []string{"api", "API"}, wantErr: fmt.Errorf(MsgMonitorNotResolved, "API")},
+		{name: "should return error listing the ids when more than one monitor matches", names: []string{"web"}, wantErr: fmt.Errorf(MsgAmbiguousMonitor, "web", "3, 4")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testmonitorservice := &testMonitorService{}
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(monitors, nil).Once()
+			defer testmonitorservice.AssertExpectations(t)
+			monitorservice := &MonitorService{IService: testmonitorservice}
+
+			got, err := monitorservice.ResolveMonitorIdsByFriendlyName(tt.names)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("ResolveMonitorIdsByFriendlyName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveMonitorIdsByFriendlyName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,48 +1,34 @@
 package monitor
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/provider"
 	"github.com/onaio/uptimerobot-tooling/pkg/service"
 	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
 	log "github.com/sirupsen/logrus"
-	"math"
-	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
-var staticPropertiesToResolve = map[string]map[string]uint8{
-	httputil.TypeField: {
-		"HTTP":      1,
-		"HTTPS":     1,
-		"Keyword":   2,
-		"Ping":      3,
-		"Port":      4,
-		"Heartbeat": 5,
-	},
-	httputil.SubTypeField: {
-		"HTTP":  1,
-		"HTTPS": 2,
-		"FTP":   3,
-		"SMTP":  4,
-		"POP3":  5,
-		"IMAP":  6,
-	},
-	httputil.KeywordTypeField: {
-		"exists":     1,
-		"not exists": 2,
-	},
-	httputil.KeywordCaseTypeField: {
-		"case sensitive":   0,
-		"case insensitive": 1,
-	},
-	httputil.HttpAuthTypeField: {
-		"Basic":           1,
-		"Digest":          2,
-		"HTTP Basic Auth": 1,
-	},
+// Fields that make up the body of the requests sent by HTTP and keyword monitors.
+var httpBodyFields = []string{httputil.PostTypeField, httputil.PostValueField, httputil.PostContentTypeField}
+
+// Methods whose requests cannot have a body.
+var httpMethodsWithoutBody = map[model.HttpMethod]bool{model.HttpMethodHead: true, model.HttpMethodGet: true, model.HttpMethodOptions: true}
+
+// Fields that can be given as lists or objects, they are encoded as the API expects them before monitors are compared or sent.
+var structuredFields = []string{
+	httputil.AlertContactsField,
+	httputil.MWindowsField,
+	httputil.CustomHttpHeadersField,
+	httputil.CustomHttpStatusesField,
+	httputil.PostValueField,
 }
 
 const (
@@ -50,6 +36,8 @@ const (
 	MonitorAlertContactsDelimiterEnv             = "MONITOR_ALERT_CONTACTS_DELIMITER"
 	MonitorAlertContactsResolveByFriendlyNameEnv = "MONITOR_RESOLVE_ALERT_CONTACTS_BY_FRIENDLY_NAME"
 	MonitorResolveByFriendlyNameEnv              = "MONITOR_RESOLVE_BY_FRIENDLY_NAME"
+	MonitorConcurrencyEnv                        = "MONITOR_CONCURRENCY"
+	MonitorContinueOnErrorEnv                    = "MONITOR_CONTINUE_ON_ERROR"
 )
 const (
 	AlertContactsAttribDelimiter = "_"
@@ -57,61 +45,117 @@ const (
 )
 
 const (
-	MsgAlertContactFetchErr                   = "error occurred when fetching alert contacts: %s was returned and %s was sent"
 	MsgAlertContactNotResolved                = "alert contacts %s could not resolved to an id"
 	MsgFieldIsInvalid                         = "%s field invalid"
 	MsgMappingNotFound                        = "mapping for %s -> %s not found"
 	MsgNoAlertContactsFound                   = "no alert contacts found"
 	MsgMonitorDoesNotExist                    = "monitor does not exist"
+	MsgMonitorNotResolved                     = "monitor %s could not be resolved to an id"
 	MsgFieldMissing                           = "%s needs to be specified"
 	MsgCheckIfMonitorHasRequiredFields        = "checking if provided monitor has all the required fields"
-	MsgOriginalAndProviderMonitorConflictType = "original and provided monitor have conflicting types (orig: %v and provided: %v) will be recreated check https://uptimerobot.com/#editMonitorWrap"
+	MsgOriginalAndProviderMonitorConflictType = "original and provided monitor have conflicting types (orig: %v and provided: %v) it needs to be recreated check https://uptimerobot.com/#editMonitorWrap"
 	MsgActionNotSupported                     = "%s action is not supported"
-	MsgUnknownErr                             = "unknown error %s"
+	MsgHttpBodyNotAllowed                     = "%s requests cannot have a body, remove %s or use POST, PUT, PATCH or DELETE as http_method"
+	MsgHttpMethodMissing                      = "%s needs http_method to be POST, PUT, PATCH or DELETE"
+	MsgUnknownErr                             = serviceutil.MsgUnknownErr
 )
 
-func (service *MonitorService) HandleRequest(dataMapInterface []map[string]interface{}, action model.Args) []map[string]interface{} {
+func (service *MonitorService) HandleRequest(ctx context.Context, dataMapInterface []map[string]interface{}, action model.Args) []map[string]interface{} {
+	service.ctx = ctx
+	// alert contacts and maintenance windows are fetched at most once per request
+	service.alertContacts = &alertContactIndex{}
+	service.mWindowNames = &mWindowNameIndex{}
+	service.journal = nil
+	if service.Journal != "" {
+		service.journal = &journal{path: service.Journal}
+		defer service.journal.close()
+	}
+	if action == model.Apply {
+		return service.ApplyRequest(dataMapInterface)
+	}
+	if action == model.Export {
+		monitors, err := service.ExportRequest()
+		resultArrayMap := createResultObject(model.Result{ErrorResultField: err}, 0, []map[string]interface{}{{}})
+		if err == nil {
+			resultArrayMap[0][model.DataResultField] = monitors
+		}
+		return resultArrayMap
+	}
+
 	resultArrayMap := make([]map[string]interface{}, len(dataMapInterface))
-	for idx, dataMap := range dataMapInterface {
-		resultArrayMap[idx] = make(map[string]interface{})
-		if action == model.Create || action == model.Update {
-			if err := service.isValidPayload(dataMap, action); err != nil {
+	serviceutil.RunPool(ctx, service.Concurrency, len(dataMapInterface), func(idx int) bool {
+		itemService := service.forItem(dataMapInterface[idx])
+		itemService.source = service.sourceOf(idx)
+		return itemService.handleItem(idx, dataMapInterface[idx], action, resultArrayMap) || service.ContinueOnError
+	})
+	return resultArrayMap
+}
+
+/*
+*
+Handles dataMap, the item at idx, and populates its result on resultArrayMap. Returns false when the item failed so that
+no further items are handled unless ContinueOnError is set.
+*/
+func (service *MonitorService) handleItem(idx int, dataMap map[string]interface{}, action model.Args, resultArrayMap []map[string]interface{}) bool {
+	resultArrayMap[idx] = make(map[string]interface{})
+	if action == model.Create || action == model.Update {
+		if err := service.isValidPayload(dataMap, action); err != nil {
+			resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+			return false
+		}
+
+		if err := service.resolveMonitorProperties(dataMap); err != nil {
+			resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+			return false
+		}
+
+		if action == model.Update {
+			if err := service.UpdateRequest(dataMap); err != nil {
 				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
-				return resultArrayMap
+				return false
 			}
-
-			if err := service.resolveMonitorProperties(dataMap); err != nil {
+		} else {
+			if err := service.CreateRequest(dataMap); err != nil {
 				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
-				return resultArrayMap
+				return false
 			}
+		}
+		resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+	} else if action == model.Delete {
+		service.logger().Infof("deleting %v monitor", dataMap[httputil.FriendlyNameField])
 
-			if action == model.Update {
-				if err := service.UpdateRequest(dataMap); err != nil {
-					resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
-					return resultArrayMap
-				}
-			} else {
-				if err := service.CreateRequest(dataMap); err != nil {
-					resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
-					return resultArrayMap
-				}
-			}
-			resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
-		} else if action == model.Delete {
-			log.Infof("deleting %v monitor", dataMap[httputil.FriendlyNameField])
+		if err := service.DeleteRequest(dataMap); err != nil {
+			resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+			return false
+		}
+		resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
 
-			if err := service.DeleteRequest(dataMap); err != nil {
-				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
-				return resultArrayMap
-			}
-			resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+	} else if action == model.Restore {
+		if err := service.RestoreRequest(dataMap); err != nil {
+			resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+			return false
+		}
+		resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
 
-		} else {
-			log.Fatalf(MsgActionNotSupported, action)
+	} else if action == model.Get || action == model.List {
+		monitors, err := service.ListRequest(dataMap, action == model.Get)
+		if err != nil {
+			resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+			return false
 		}
+		service.maskCredentials(monitors)
+		resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+		resultArrayMap[idx][model.DataResultField] = monitors
+
+	} else {
+		log.Fatalf(MsgActionNotSupported, action)
 	}
 
-	return resultArrayMap
+	if changes := service.takeChanges(); len(changes) > 0 {
+		resultArrayMap[idx][model.PlanResultField] = changes
+	}
+	service.addAction(resultArrayMap[idx])
+	return true
 }
 
 /*
@@ -150,14 +194,14 @@ func (service *MonitorService) isValidPayload(dataMap map[string]interface{}, ar
 			if dataMap[httputil.TypeField] == nil {
 				validityError = fmt.Errorf(MsgFieldMissing, httputil.TypeField)
 			} else {
-				typeValue := fmt.Sprint(dataMap[httputil.TypeField])
-				if strings.EqualFold(httputil.PortField, typeValue) && (dataMap[httputil.SubTypeField] == nil || dataMap[httputil.PortField] == nil) {
+				monitorType, _ := model.MonitorEnums[httputil.TypeField].Resolve(dataMap[httputil.TypeField])
+				if monitorType == model.MonitorTypePort.Int() && (dataMap[httputil.SubTypeField] == nil || dataMap[httputil.PortField] == nil) {
 					if dataMap[httputil.SubTypeField] == nil {
 						validityError = fmt.Errorf("%s monitoring requires %s", "port", httputil.SubTypeField)
 					} else if dataMap[httputil.PortField] == nil {
 						validityError = fmt.Errorf("%s monitoring requires %s", "port", httputil.PortField)
 					}
-				} else if strings.EqualFold(httputil.KeywordField, typeValue) {
+				} else if monitorType == model.MonitorTypeKeyword.Int() {
 					if dataMap[httputil.KeywordTypeField] == nil {
 						validityError = fmt.Errorf("%s monitoring requires %s", "keyword", httputil.KeywordTypeField)
 					} else if dataMap[httputil.KeywordValueField] == nil {
@@ -176,16 +220,58 @@ func (service *MonitorService) isValidPayload(dataMap map[string]interface{}, ar
 
 		}
 	}
+	if validityError == nil {
+		validityError = validateHttpBody(dataMap, args)
+	}
 	return validityError
 }
 
+/*
+*
+Checks that post_type, post_value and post_content_type are only given along with an http_method that allows a body. On
+update the http_method may be left out since the monitor may already use such a method.
+*/
+func validateHttpBody(dataMap map[string]interface{}, args model.Args) error {
+	for _, field := range httpBodyFields {
+		if dataMap[field] == nil {
+			continue
+		}
+		if dataMap[httputil.HttpMethodField] == nil {
+			if args == model.Update {
+				return nil
+			}
+			return fmt.Errorf(MsgHttpMethodMissing, field)
+		}
+		id, resolved := model.MonitorEnums[httputil.HttpMethodField].Resolve(dataMap[httputil.HttpMethodField])
+		if !resolved {
+			return fmt.Errorf(MsgMappingNotFound, httputil.HttpMethodField, serviceutil.ToString(dataMap[httputil.HttpMethodField]))
+		}
+		if method := model.HttpMethod(id); httpMethodsWithoutBody[method] {
+			return fmt.Errorf(MsgHttpBodyNotAllowed, method, field)
+		}
+	}
+	return nil
+}
+
 /*
 *
 Resolves Monitor Properties:
+0. Drops credentials and custom_http_headers masked by get and list (see maskCredentials) so that their output does not
+overwrite them.
 1. Resolves alert contact friendly name to id if friendly_name otherwise it returns the id check resolveAlertContactByFriendlyName if MONITOR_RESOLVE_ALERT_CONTACTS_BY_FRIENDLY_NAME env is true
-2. Resolves monitor properties mapped on staticPropertiesToResolve.
+2. Resolves maintenance window friendly names to ids, see resolveMWindows.
+3. Encodes and validates the fields in structuredFields, see httputil.EncodeField.
+4. Resolves the enum properties of monitors by their names or numbers, see model.MonitorEnums.
 */
 func (service *MonitorService) resolveMonitorProperties(dataMap map[string]interface{}) error {
+	for _, field := range credentialFields {
+		if dataMap[field] == redactutil.Redacted {
+			delete(dataMap, field)
+		}
+	}
+	if headers, err := httputil.EncodeField(httputil.CustomHttpHeadersField, dataMap[httputil.CustomHttpHeadersField]); err == nil && strings.Contains(headers, redactutil.Redacted) {
+		delete(dataMap, httputil.CustomHttpHeadersField)
+	}
 	resolveAlertContactByFriendlyName := false
 	_resolveAlertContactByFriendlyName, found := service.IService.LookUpEnv(MonitorAlertContactsResolveByFriendlyNameEnv)
 	if found {
@@ -207,12 +293,23 @@ func (service *MonitorService) resolveMonitorProperties(dataMap map[string]inter
 		}
 
 		if alertContacts, exists := dataMap[httputil.AlertContactsField]; exists {
-			alertContactStr := fmt.Sprint(alertContacts)
-			alertContactsArr := strings.Split(alertContactStr, delimiter)
+			var alertContactsArr []string
+			if items, ok := alertContacts.([]interface{}); ok {
+				// the items of a list are alert contacts already, their friendly_name may contain the delimiter
+				for _, item := range items {
+					alertContactsArr = append(alertContactsArr, serviceutil.ToString(item))
+				}
+			} else {
+				alertContactsArr = strings.Split(serviceutil.ToString(alertContacts), delimiter)
+			}
+			alertContactIndex, err := service.alertContactIndex()
+			if err != nil {
+				return err
+			}
 			resolvedAlertContacts := make([]string, len(alertContactsArr))
 			for index, value := range alertContactsArr {
 				alertContact := strings.Split(value, attribDelimiter)
-				alertContactFriendlyName, err := service.resolveAlertContactByFriendlyName(alertContact[0])
+				alertContactFriendlyName, err := service.resolveAlertContact(alertContactIndex, alertContact[0])
 				if err != nil {
 					return err
 				}
@@ -226,14 +323,29 @@ func (service *MonitorService) resolveMonitorProperties(dataMap map[string]inter
 			dataMap[httputil.AlertContactsField] = resolvedAlertContactsStr
 		}
 	}
+	if mWindows, exists := dataMap[httputil.MWindowsField]; exists && mWindows != nil {
+		resolved, err := service.resolveMWindows(mWindows)
+		if err != nil {
+			return err
+		}
+		dataMap[httputil.MWindowsField] = resolved
+	}
+	for _, field := range structuredFields {
+		if value, exists := dataMap[field]; exists {
+			encoded, err := httputil.EncodeField(field, value)
+			if err != nil {
+				return err
+			}
+			dataMap[field] = encoded
+		}
+	}
 	for property, value := range dataMap {
-		if _, exists := staticPropertiesToResolve[property]; exists {
-			_value := fmt.Sprint(value)
-			if _, exists := staticPropertiesToResolve[property][_value]; exists {
-				dataMap[property] = staticPropertiesToResolve[property][_value]
-			} else {
-				return fmt.Errorf(MsgMappingNotFound, property, _value)
+		if enum, exists := model.MonitorEnums[property]; exists {
+			id, resolved := enum.Resolve(value)
+			if !resolved {
+				return fmt.Errorf(MsgMappingNotFound, property, serviceutil.ToString(value))
 			}
+			dataMap[property] = uint8(id)
 		}
 
 	}
@@ -242,61 +354,54 @@ func (service *MonitorService) resolveMonitorProperties(dataMap map[string]inter
 
 }
 
-/**
-Returns alert contact id if alertContactFriendlyName = alertContactFriendlyName[-] otherwise it will return an error
+/*
+*
+Replaces the friendly_names among mWindows, a list or ids separated by the default delimiter, with the ids of the
+maintenance windows e.g. of an export. The maintenance windows are only fetched when an item is not an id, mWindows is
+returned as is then.
 */
-
-func (service *MonitorService) resolveAlertContactByFriendlyName(alertContactFriendlyName string) (string, error) {
-	pages := -1
-	offset := 0
-	completed := false
-	requestBody := make(map[string]interface{})
-	for !completed && (pages == -1 || pages > offset) {
-		requestBody[httputil.OffsetField] = offset
-		resultMap, err := service.IService.HttpInitiatePostRequest(httputil.GetAlertContactsEndpoint, requestBody)
-		if err != nil {
-			return "", err
+func (service *MonitorService) resolveMWindows(mWindows interface{}) (interface{}, error) {
+	var items []string
+	if list, ok := mWindows.([]interface{}); ok {
+		for _, item := range list {
+			items = append(items, serviceutil.ToString(item))
 		}
-		if resultMap != nil && resultMap[httputil.TotalField] != nil && resultMap[httputil.LimitField] != nil && resultMap[httputil.AlertContactsField] != nil {
-			total, err := strconv.Atoi(fmt.Sprint(resultMap[httputil.TotalField]))
-			if err != nil {
-				return "", err
-			}
-			limit, err := strconv.Atoi(fmt.Sprint(resultMap[httputil.LimitField]))
-			if err != nil {
-				return "", err
-			}
-			if total < 1 {
-				return "", errors.New(MsgNoAlertContactsFound)
-			} else {
-				if pages == -1 {
-					pages = int(math.Ceil(float64(total / limit)))
-				}
-				alertContacts := resultMap[httputil.AlertContactsField].([]interface{})
-				for _, alertContact := range alertContacts {
-					alertContact := alertContact.(map[string]interface{})
-					if alertContact[httputil.FriendlyNameField] != nil && alertContact[httputil.IdField] != nil && fmt.Sprint(alertContact[httputil.FriendlyNameField]) == alertContactFriendlyName {
-						alertContactFriendlyName = fmt.Sprint(alertContact[httputil.IdField])
-						completed = true
-						break
-					}
-				}
-
-				offset++
-			}
-
-		} else {
-			return "", fmt.Errorf(MsgAlertContactFetchErr, resultMap, requestBody)
-
+	} else {
+		items = strings.Split(serviceutil.ToString(mWindows), httputil.ListDelimiter)
+	}
+	named := false
+	for _, item := range items {
+		if _, err := serviceutil.ToInt(item); err != nil {
+			named = true
 		}
 	}
+	if !named {
+		return mWindows, nil
+	}
 
-	_, err := strconv.Atoi(alertContactFriendlyName)
+	names, err := service.fetchMWindowNames()
 	if err != nil {
-		return "", fmt.Errorf(MsgAlertContactNotResolved, alertContactFriendlyName)
+		return nil, err
+	}
+	for idx, item := range items {
+		if items[idx], err = resolveMWindow(names, item); err != nil {
+			return nil, err
+		}
 	}
+	return strings.Join(items, httputil.ListDelimiter), nil
+}
 
-	return alertContactFriendlyName, nil
+/*
+*
+Returns the id of the alert contact named alertContactFriendlyName or alertContactFriendlyName itself when it is an id,
+see resolveAlertContact.
+*/
+func (service *MonitorService) resolveAlertContactByFriendlyName(alertContactFriendlyName string) (string, error) {
+	index, err := service.alertContactIndex()
+	if err != nil {
+		return "", err
+	}
+	return service.resolveAlertContact(index, alertContactFriendlyName)
 }
 
 func (service *MonitorService) UpdateRequest(dataMap map[string]interface{}) error {
@@ -306,70 +411,92 @@ func (service *MonitorService) UpdateRequest(dataMap map[string]interface{}) err
 		if err != nil {
 			return err
 		}
-		_monitorArr, err := service.searchMonitorByFieldMap(map[string]interface{}{
-			httputil.SearchField: dataMap[httputil.FriendlyNameField],
-		})
+		remoteMonitor, err := service.findMonitorByFriendlyName(fmt.Sprint(dataMap[httputil.FriendlyNameField]), true, service.FuzzyMatch)
 		if err != nil {
 			return err
 		}
-		monitorArr = _monitorArr
+		if remoteMonitor != nil {
+			monitorArr = []interface{}{remoteMonitor}
+		}
 
 	} else {
-		_monitorArr, err := service.searchMonitorByFieldMap(map[string]interface{}{
+		_monitorArr, err := service.searchMonitorByFieldMap(withMonitorDetails(map[string]interface{}{
 			httputil.MonitorsField: dataMap[httputil.IdField],
-		})
+		}))
 		if err != nil {
 			return err
 		}
 		monitorArr = _monitorArr
-		log.Infof("%v", monitorArr)
+		service.logger().Infof("%v", redactutil.Value(monitorArr))
 
 	}
 	if monitorArr != nil && len(monitorArr) > 0 {
-		log.Infof("%s %s %s", "updating", dataMap[httputil.FriendlyNameField], "monitor")
-
-		originalMonitor := monitorArr[0].(map[string]interface{})
-		dataMap[httputil.IdField] = originalMonitor[httputil.IdField]
-
-		if dataMap[httputil.TypeField] != nil && fmt.Sprint(dataMap[httputil.TypeField]) != fmt.Sprint(originalMonitor[httputil.TypeField]) {
-			log.Warningf(MsgOriginalAndProviderMonitorConflictType, fmt.Sprint(originalMonitor[httputil.TypeField]), fmt.Sprint(dataMap[httputil.TypeField]))
-			log.Info(MsgCheckIfMonitorHasRequiredFields)
-
-			err := service.isValidPayload(dataMap, model.Create)
-			if err != nil {
-				return err
-			}
+		return service.updateMonitor(dataMap, monitorArr[0].(map[string]interface{}))
+	} else {
+		if err := service.CreateRequest(dataMap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			_, err = service.InitiateRequest(httputil.DeleteMonitorEndpoint, originalMonitor)
-			if err != nil {
-				return err
-			}
+/*
+*
+Edits originalMonitor with the values in dataMap, if the types conflict the monitor is deleted and recreated since the
+API does not allow changing the type of a monitor (see recreateMonitor). The edit is skipped when no field differs from
+originalMonitor (see diffMonitor).
+*/
+func (service *MonitorService) updateMonitor(dataMap map[string]interface{}, originalMonitor map[string]interface{}) error {
+	service.logger().Infof("%s %s %s", "updating", dataMap[httputil.FriendlyNameField], "monitor")
 
-			_, err = service.InitiateRequest(httputil.NewMonitorEndpoint, dataMap)
-			if err != nil {
-				return err
-			}
+	dataMap[httputil.IdField] = originalMonitor[httputil.IdField]
 
-		} else {
-			if _, err := service.InitiateRequest(httputil.EditMonitorEndpoint, dataMap); err != nil {
-				return err
-			}
+	if dataMap[httputil.TypeField] != nil && serviceutil.ToString(dataMap[httputil.TypeField]) != serviceutil.ToString(originalMonitor[httputil.TypeField]) {
+		service.logger().Warningf(MsgOriginalAndProviderMonitorConflictType, serviceutil.ToString(originalMonitor[httputil.TypeField]), serviceutil.ToString(dataMap[httputil.TypeField]))
+		service.logger().Info(MsgCheckIfMonitorHasRequiredFields)
 
+		if err := service.isValidPayload(dataMap, model.Create); err != nil {
+			return err
 		}
+		return service.recreateMonitor(dataMap, originalMonitor)
+	}
 
-	} else {
-		if err := service.CreateRequest(dataMap); err != nil {
-			return err
+	fieldChanges := diffMonitor(dataMap, originalMonitor)
+	if len(fieldChanges) == 0 {
+		service.logger().Infof(MsgMonitorUnchanged, dataMap[httputil.FriendlyNameField])
+		if service.DryRun {
+			service.recordChange(model.Unchanged, dataMap, originalMonitor)
+		} else {
+			service.recordAction(model.Unchanged, serviceutil.ToString(originalMonitor[httputil.IdField]))
 		}
+		return nil
+	}
+	if service.DryRun {
+		service.recordChange(model.Updated, dataMap, originalMonitor)
+		return nil
+	}
+	if _, err := service.writeMonitor(httputil.EditMonitorEndpoint, dataMap, originalMonitor); err != nil {
+		return err
+	}
+	service.recordAction(model.Updated, serviceutil.ToString(originalMonitor[httputil.IdField]))
+	service.changedFields = make([]string, len(fieldChanges))
+	for idx, fieldChange := range fieldChanges {
+		service.changedFields[idx] = fieldChange.Field
 	}
 	return nil
 }
 
 func (service *MonitorService) CreateRequest(dataMap map[string]interface{}) error {
-	log.Infof("%s %s %s", "creating", dataMap[httputil.FriendlyNameField], "monitor")
-	if _, err := service.InitiateRequest(httputil.NewMonitorEndpoint, dataMap); err != nil {
+	if service.DryRun {
+		service.recordChange(model.Created, dataMap, nil)
+		return nil
+	}
+	service.logger().Infof("%s %s %s", "creating", dataMap[httputil.FriendlyNameField], "monitor")
+	resultMap, err := service.writeMonitor(httputil.NewMonitorEndpoint, dataMap, nil)
+	if err != nil {
 		return err
 	}
+	service.recordAction(model.Created, monitorIdOf(resultMap))
 	return nil
 }
 
@@ -390,38 +517,78 @@ func (service *MonitorService) monitorResolvableByFriendlyName() (bool, error) {
 }
 
 func (service *MonitorService) DeleteRequest(dataMap map[string]interface{}) error {
+	var remoteMonitor map[string]interface{}
 	if dataMap[httputil.IdField] != nil {
-		if _, err := service.InitiateRequest(httputil.DeleteMonitorEndpoint, dataMap); err != nil {
+		monitorArr, err := service.searchMonitorByFieldMap(withMonitorDetails(map[string]interface{}{
+			httputil.MonitorsField: dataMap[httputil.IdField],
+		}))
+		if err != nil {
 			return err
 		}
+		if len(monitorArr) > 0 {
+			remoteMonitor, _ = monitorArr[0].(map[string]interface{})
+		}
 	} else if dataMap[httputil.FriendlyNameField] != nil {
 		if _, err := service.monitorResolvableByFriendlyName(); err != nil {
 			return err
 		}
 
-		monitorArr, err := service.searchMonitorByFieldMap(map[string]interface{}{
-			httputil.SearchField: dataMap[httputil.FriendlyNameField],
-		})
+		_remoteMonitor, err := service.findMonitorByFriendlyName(fmt.Sprint(dataMap[httputil.FriendlyNameField]), true, false)
 		if err != nil {
 			return err
 		}
-
-		if monitorArr != nil && len(monitorArr) > 0 {
-			remoteMonitor := monitorArr[0].(map[string]interface{})
-			_, err = service.InitiateRequest(httputil.DeleteMonitorEndpoint, remoteMonitor)
-			if err != nil {
-				return err
-			}
-		} else {
-			return errors.New(MsgMonitorDoesNotExist)
-		}
-
+		remoteMonitor = _remoteMonitor
 	} else {
 		return fmt.Errorf(MsgFieldMissing, "id or friendly_name")
 	}
+
+	if remoteMonitor == nil {
+		return errors.New(MsgMonitorDoesNotExist)
+	}
+	return service.deleteMonitor(remoteMonitor)
+}
+
+/*
+*
+Deletes remoteMonitor, as returned by getMonitors with its details, once it is written to the trash when there is one
+(see trashMonitor).
+*/
+func (service *MonitorService) deleteMonitor(remoteMonitor map[string]interface{}) error {
+	if service.DryRun {
+		service.recordChange(model.Deleted, map[string]interface{}{}, remoteMonitor)
+		return nil
+	}
+	if _, err := service.trashMonitor(remoteMonitor); err != nil {
+		return fmt.Errorf(MsgMonitorNotTrashed, remoteMonitor[httputil.FriendlyNameField], err)
+	}
+	id := serviceutil.ToString(remoteMonitor[httputil.IdField])
+	if _, err := service.writeMonitor(httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: id}, remoteMonitor); err != nil {
+		return err
+	}
+	service.recordAction(model.Deleted, id)
 	return nil
 }
 
+/*
+*
+Returns the id of the monitor whose friendly_name is monitorFriendlyName. Numeric values that do not match a monitor
+friendly_name are assumed to already be an id.
+*/
+func (service *MonitorService) ResolveMonitorIdByFriendlyName(monitorFriendlyName string) (string, error) {
+	remoteMonitor, err := service.findMonitorByFriendlyName(monitorFriendlyName, false, false)
+	if err != nil {
+		return "", err
+	}
+	if remoteMonitor != nil && remoteMonitor[httputil.IdField] != nil {
+		return serviceutil.ToString(remoteMonitor[httputil.IdField]), nil
+	}
+
+	if _, err := strconv.Atoi(monitorFriendlyName); err != nil {
+		return "", fmt.Errorf(MsgMonitorNotResolved, monitorFriendlyName)
+	}
+	return monitorFriendlyName, nil
+}
+
 func (service *MonitorService) searchMonitorByFieldMap(searchData map[string]interface{}) ([]interface{}, error) {
 	resultMap, err := service.InitiateRequest(httputil.GetMonitorsEndpoint, searchData)
 	if err != nil {
@@ -439,38 +606,198 @@ func (service *MonitorService) searchMonitorByFieldMap(searchData map[string]int
 	return nil, nil
 }
 
-func (service *MonitorService) InitiateRequest(endpoint string, data map[string]interface{}) (map[string]interface{}, error) {
-	resultMap, err := service.IService.HttpInitiatePostRequest(endpoint, data)
+/*
+*
+Returns a copy of the service to handle dataMap with, so that items can be handled concurrently. Its log lines carry the
+friendly_name of the monitor.
+*/
+func (service *MonitorService) forItem(dataMap map[string]interface{}) *MonitorService {
+	entry := service.logger()
+	if dataMap[httputil.FriendlyNameField] != nil {
+		entry = entry.WithField(model.MonitorNameResultField, dataMap[httputil.FriendlyNameField])
+	} else if dataMap[httputil.IdField] != nil {
+		entry = entry.WithField(httputil.IdField, serviceutil.ToString(dataMap[httputil.IdField]))
+	}
+	copied := *service
+	// the state of the item being handled starts empty
+	copied.Concurrency = 1
+	copied.source = ""
+	copied.changes = nil
+	copied.actionTaken = ""
+	copied.remoteId = ""
+	copied.changedFields = nil
+	copied.entry = entry
+	return &copied
+}
 
-	if err != nil {
-		return nil, err
+// Returns the input the item at idx was read from, see Sources.
+func (service *MonitorService) sourceOf(idx int) string {
+	if idx < len(service.Sources) {
+		return service.Sources[idx]
 	}
-	if resultMap != nil && resultMap[httputil.ErrorField] != nil {
-		returnError := resultMap[httputil.ErrorField].(map[string]interface{})
-		if returnError[httputil.MessageField] != nil {
-			return nil, errors.New(fmt.Sprint(returnError[httputil.MessageField]))
-		} else {
-			return nil, fmt.Errorf(MsgUnknownErr, resultMap)
-		}
-	} else {
-		return resultMap, nil
+	return ""
+}
+
+// Returns the context of the request being handled, see HandleRequest.
+func (service *MonitorService) requestContext() context.Context {
+	if service.ctx == nil {
+		return context.Background()
+	}
+	return service.ctx
+}
+
+// Records the write done to the item (see addAction), dry runs record changes instead.
+func (service *MonitorService) recordAction(action string, id string) {
+	service.actionTaken = action
+	service.remoteId = id
+}
+
+// Adds the recorded action and remote id to result and resets them.
+func (service *MonitorService) addAction(result map[string]interface{}) {
+	if service.actionTaken != "" {
+		result[model.ActionResultField] = service.actionTaken
+	}
+	if service.remoteId != "" {
+		result[model.IdResultField] = service.remoteId
+	}
+	if len(service.changedFields) > 0 {
+		result[model.ChangedFieldsResultField] = service.changedFields
+	}
+	service.actionTaken, service.remoteId, service.changedFields = "", "", nil
+}
+
+// Returns the id of the monitor in the response of newMonitor, editMonitor or deleteMonitor.
+func monitorIdOf(resultMap map[string]interface{}) string {
+	if monitor, ok := resultMap[httputil.MonitorField].(map[string]interface{}); ok && monitor[httputil.IdField] != nil {
+		return serviceutil.ToString(monitor[httputil.IdField])
 	}
+	return ""
+}
+
+func (service *MonitorService) logger() *log.Entry {
+	if service.entry == nil {
+		return log.NewEntry(log.StandardLogger())
+	}
+	return service.entry
+}
+
+func (service *MonitorService) InitiateRequest(endpoint string, data map[string]interface{}) (map[string]interface{}, error) {
+	if service.DryRun && writeEndpoints[endpoint] {
+		service.logger().Warnf(MsgDryRunSkippedRequest, endpoint)
+		return map[string]interface{}{}, nil
+	}
+	return serviceutil.InitiateRequest(service.requestContext(), service.IService, endpoint, data)
 }
 
 func New() *MonitorService {
-	monitorservice := &MonitorService{}
+	monitorservice := &MonitorService{ContinueOnError: true}
 	monitorservice.IService = monitorservice
+	if val, found := monitorservice.LookUpEnv(MonitorDryRunEnv); found {
+		dryRun, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Fatalf("invalid %s: %v", MonitorDryRunEnv, err)
+		}
+		monitorservice.DryRun = dryRun
+	}
+	if val, found := monitorservice.LookUpEnv(MonitorConcurrencyEnv); found {
+		concurrency, err := strconv.Atoi(val)
+		if err != nil || concurrency < 1 {
+			log.Fatalf("invalid %s: %s needs to be a positive number", MonitorConcurrencyEnv, val)
+		}
+		monitorservice.Concurrency = concurrency
+	}
+	if val, found := monitorservice.LookUpEnv(MonitorContinueOnErrorEnv); found {
+		continueOnError, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Fatalf("invalid %s: %v", MonitorContinueOnErrorEnv, err)
+		}
+		monitorservice.ContinueOnError = continueOnError
+	}
+	if val, found := monitorservice.LookUpEnv(MonitorFuzzyMatchEnv); found {
+		fuzzyMatch, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Fatalf("invalid %s: %v", MonitorFuzzyMatchEnv, err)
+		}
+		monitorservice.FuzzyMatch = fuzzyMatch
+	}
+	if val, found := monitorservice.LookUpEnv(MonitorAllowRecreateEnv); found {
+		allowRecreate, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Fatalf("invalid %s: %v", MonitorAllowRecreateEnv, err)
+		}
+		monitorservice.AllowRecreate = allowRecreate
+	}
+	if val, found := monitorservice.LookUpEnv(MonitorShowSecretsEnv); found {
+		showSecrets, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Fatalf("invalid %s: %v", MonitorShowSecretsEnv, err)
+		}
+		monitorservice.ShowSecrets = showSecrets
+	}
+	monitorservice.SnapshotDir, _ = monitorservice.LookUpEnv(MonitorSnapshotDirEnv)
+	monitorservice.TrashDir, _ = monitorservice.LookUpEnv(MonitorTrashDirEnv)
+	monitorservice.Journal, _ = monitorservice.LookUpEnv(MonitorJournalEnv)
+	if monitorservice.Operator, _ = monitorservice.LookUpEnv(MonitorOperatorEnv); monitorservice.Operator == "" {
+		monitorservice.Operator = currentOperator()
+	}
+	monitorservice.AlertContactsCache, _ = monitorservice.LookUpEnv(MonitorAlertContactsCacheEnv)
+	if val, found := monitorservice.LookUpEnv(MonitorAlertContactsCacheTTLEnv); found {
+		ttl, err := time.ParseDuration(val)
+		if err != nil || ttl <= 0 {
+			log.Fatalf("invalid %s: %s needs to be a duration e.g. 10m", MonitorAlertContactsCacheTTLEnv, val)
+		}
+		monitorservice.AlertContactsCacheTTL = ttl
+	}
 	return monitorservice
 }
 
 type MonitorService struct {
 	service.IService
+	// DryRun records the changes writes would make (see recordChange) without calling the write endpoints.
+	DryRun bool
+	// Concurrency is the number of items handled at the same time.
+	Concurrency int
+	// ContinueOnError keeps handling the remaining items after an item failed, its error is kept on its result.
+	ContinueOnError bool
+	// AllowRecreate lets an update that changes the type delete and recreate the monitor, see recreateMonitor.
+	AllowRecreate bool
+	// SnapshotDir is where monitors are written before they are recreated.
+	SnapshotDir string
+	// TrashDir is where monitors are written before they are deleted or recreated (see RestoreRequest), not used when empty.
+	TrashDir string
+	// Journal is the file every write is appended to (see writeMonitor), writes are not journaled when it is empty.
+	Journal string
+	// Operator is who the writes are journaled as, the user running the tool by default.
+	Operator string
+	// Sources are the inputs the items were read from by index e.g. monitors.json[2], they are journaled with the writes.
+	Sources []string
+	// ShowSecrets makes get and list return the credentials of monitors, they are masked otherwise (see maskCredentials).
+	ShowSecrets bool
+	// FuzzyMatch lets update fall back to a case-insensitive or partial friendly_name match, for interactive use.
+	FuzzyMatch bool
+	// AlertContactsCache is a file alert contacts are cached in across runs for AlertContactsCacheTTL, not used when empty.
+	AlertContactsCache    string
+	AlertContactsCacheTTL time.Duration
+	alertContacts         *alertContactIndex
+	mWindowNames          *mWindowNameIndex
+	journal               *journal
+	// source is the input the item being handled was read from.
+	source  string
+	changes []model.Change
+	// actionTaken and remoteId are what the last write did to the item and the id of the monitor it was done to.
+	actionTaken string
+	remoteId    string
+	// changedFields are the fields the last edit changed.
+	changedFields []string
+	// ctx is the context of the request being handled.
+	ctx   context.Context
+	entry *log.Entry
 }
 
-func (service *MonitorService) HttpInitiatePostRequest(endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
-	return httputil.New().InitiatePostRequest(endpoint, dataMap)
+func (service *MonitorService) HttpInitiatePostRequest(ctx context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	return httputil.New().InitiatePostRequest(ctx, endpoint, dataMap)
 }
 
 func (service *MonitorService) LookUpEnv(variable string) (string, bool) {
-	return os.LookupEnv(variable)
+	return provider.LookUpEnv(variable)
 }
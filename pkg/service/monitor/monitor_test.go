@@ -1,11 +1,13 @@
 package monitor
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/onaio/uptimerobot-tooling/pkg/model"
 	"github.com/onaio/uptimerobot-tooling/pkg/service"
 	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
 	"github.com/stretchr/testify/mock"
 	"reflect"
 	"testing"
@@ -16,12 +18,12 @@ type testMonitorService struct {
 	mock.Mock
 }
 
-func (t *testMonitorService) HandleRequest(dataMapInterface []map[string]interface{}, action model.Args) []map[string]interface{} {
+func (t *testMonitorService) HandleRequest(_ context.Context, dataMapInterface []map[string]interface{}, action model.Args) []map[string]interface{} {
 	args := t.Called(dataMapInterface, action)
 	return args.Get(0).([]map[string]interface{})
 }
 
-func (t *testMonitorService) HttpInitiatePostRequest(endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+func (t *testMonitorService) HttpInitiatePostRequest(_ context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
 	args := t.Called(endpoint, dataMap)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -105,7 +107,9 @@ func TestMonitorService_HandleRequest(t *testing.T) {
 		}}},
 		{name: "should return result payload with nil err field", setupMocks: func() {
 			testmonitorservice = &testMonitorService{}
-			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+				httputil.MonitorField: map[string]interface{}{httputil.IdField: float64(777712827)},
+			}, nil)
 			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
 			monitorservice.IService = testmonitorservice
 		}, verifyMocks: func() {
@@ -114,7 +118,7 @@ func TestMonitorService_HandleRequest(t *testing.T) {
 			httputil.FriendlyNameField: "test",
 			httputil.TypeField:         "HTTP",
 			httputil.UrlField:          "https://localhost",
-		}}, model.Create}, want: []map[string]interface{}{{model.ErrorResultField: nil, model.MonitorNameResultField: "test"}}},
+		}}, model.Create}, want: []map[string]interface{}{{model.ErrorResultField: nil, model.MonitorNameResultField: "test", model.ActionResultField: model.Created, model.IdResultField: "777712827"}}},
 		{name: "should return result payload with non-nil err field", setupMocks: func() {
 			testmonitorservice = &testMonitorService{}
 			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, errors.New("unknown error"))
@@ -132,7 +136,7 @@ func TestMonitorService_HandleRequest(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupMocks()
 			defer tt.verifyMocks()
-			if got := monitorservice.HandleRequest(tt.args.data, tt.args.argument); !reflect.DeepEqual(got, tt.want) {
+			if got := monitorservice.HandleRequest(context.Background(), tt.args.data, tt.args.argument); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("HandleRequest() = %v, want %v", got, tt.want)
 			}
 		})
@@ -174,6 +178,18 @@ func TestMonitorService_isValidPayload(t *testing.T) {
 			httputil.PortField:         "8080",
 			httputil.UrlField:          "http://test.localhost",
 		}, arguments: model.Create}, want: fmt.Errorf("%s monitoring requires %s", "port", httputil.SubTypeField)},
+		{name: "should return error in port monitoring given by number or as a model.MonitorType if port is missing on create", args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "tester",
+			httputil.TypeField:         model.MonitorTypePort,
+			httputil.SubTypeField:      float64(2),
+			httputil.UrlField:          "http://test.localhost",
+		}, arguments: model.Create}, want: fmt.Errorf("%s monitoring requires %s", "port", httputil.PortField)},
+		{name: "should return error in keyword monitoring given by number if keyword_type field is missing on create", args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "tester",
+			httputil.TypeField:         "2",
+			httputil.KeywordValueField: "welcome",
+			httputil.UrlField:          "http://test.localhost",
+		}, arguments: model.Create}, want: fmt.Errorf("%s monitoring requires %s", "keyword", httputil.KeywordTypeField)},
 		{name: "should return error in keyword monitoring if keyword_type field is missing on create", args: args{dataMap: map[string]interface{}{
 			httputil.FriendlyNameField: "tester",
 			httputil.TypeField:         "Keyword",
@@ -192,6 +208,45 @@ func TestMonitorService_isValidPayload(t *testing.T) {
 			httputil.KeywordTypeField:  "exists",
 			httputil.KeywordValueField: "welcome",
 		}, arguments: model.Create}, want: fmt.Errorf(MsgFieldMissing, httputil.UrlField)},
+		{name: "should accept a body for methods that allow one on create", args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField:    "tester",
+			httputil.TypeField:            "HTTP",
+			httputil.UrlField:             "http://test.localhost",
+			httputil.HttpMethodField:      "POST",
+			httputil.PostTypeField:        "raw data",
+			httputil.PostValueField:       map[string]interface{}{"check": "deep"},
+			httputil.PostContentTypeField: "application/json",
+		}, arguments: model.Create}, want: nil},
+		{name: "should return error when a body is given for a method without one", args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "tester",
+			httputil.HttpMethodField:   "get",
+			httputil.PostValueField:    "check=deep",
+		}, arguments: model.Update}, want: fmt.Errorf(MsgHttpBodyNotAllowed, "GET", httputil.PostValueField)},
+		{name: "should return error when a body is given for a method without one given by number", args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "tester",
+			httputil.HttpMethodField:   float64(1),
+			httputil.PostValueField:    "check=deep",
+		}, arguments: model.Update}, want: fmt.Errorf(MsgHttpBodyNotAllowed, "HEAD", httputil.PostValueField)},
+		{name: "should accept a body for methods that allow one given in lowercase", args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "tester",
+			httputil.HttpMethodField:   "post",
+			httputil.PostValueField:    "check=deep",
+		}, arguments: model.Update}, want: nil},
+		{name: "should return error when a body is given for an unknown method", args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "tester",
+			httputil.HttpMethodField:   "TRACE",
+			httputil.PostValueField:    "check=deep",
+		}, arguments: model.Update}, want: fmt.Errorf(MsgMappingNotFound, httputil.HttpMethodField, "TRACE")},
+		{name: "should return error when a body is given without http_method on create", args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "tester",
+			httputil.TypeField:         "HTTP",
+			httputil.UrlField:          "http://test.localhost",
+			httputil.PostValueField:    "check=deep",
+		}, arguments: model.Create}, want: fmt.Errorf(MsgHttpMethodMissing, httputil.PostValueField)},
+		{name: "should accept a body without http_method on update", args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "tester",
+			httputil.PostValueField:    "check=deep",
+		}, arguments: model.Update}, want: nil},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -645,6 +700,124 @@ func TestMonitorService_resolveMonitorProperties(t *testing.T) {
 			httputil.UrlField:           "tester.localhost",
 			httputil.TypeField:          "gRPC",
 		}}, wantErr: true},
+		{name: "should encode structured fields", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.AlertContactsField:      []interface{}{"1_0_5", float64(2)},
+			httputil.MWindowsField:           []interface{}{float64(10), float64(11)},
+			httputil.CustomHttpHeadersField:  map[string]interface{}{"X-Token": "a"},
+			httputil.CustomHttpStatusesField: map[string]interface{}{"404": "down", "200": "up"},
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.AlertContactsField:      "1_0_5-2",
+			httputil.MWindowsField:           "10-11",
+			httputil.CustomHttpHeadersField:  `{"X-Token":"a"}`,
+			httputil.CustomHttpStatusesField: "200:1_404:0",
+		}},
+		{name: "should drop credentials masked by get and list", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "api",
+			httputil.HttpUsernameField: redactutil.Redacted,
+			httputil.HttpPasswordField: redactutil.Redacted,
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.FriendlyNameField: "api",
+		}},
+		{name: "should drop custom http headers masked by get and list", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField:      "api",
+			httputil.CustomHttpHeadersField: `{"Accept":"text/html","Authorization":"[REDACTED]"}`,
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.FriendlyNameField: "api",
+		}},
+		{name: "should resolve maintenance windows by friendly_name", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(map[string]interface{}{
+				httputil.MWindowsField: []interface{}{
+					map[string]interface{}{httputil.IdField: float64(10), httputil.FriendlyNameField: "deploy"},
+				},
+			}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.MWindowsField: "deploy-11",
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.MWindowsField: "10-11",
+		}},
+		{name: "should resolve alert contacts given as a list by friendly_name containing the delimiter", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("true", true)
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsAttribDelimiterEnv).Return("", false)
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsDelimiterEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+				"stat":  "ok",
+				"limit": float64(50),
+				"total": float64(1),
+				"alert_contacts": []interface{}{
+					map[string]interface{}{"id": float64(1000000), "friendly_name": "on-call"},
+				},
+			}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.AlertContactsField: []interface{}{"on-call_0_5"},
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.AlertContactsField: "1000000_0_5",
+		}},
+		{name: "should resolve http method and body fields", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.HttpMethodField:      "PUT",
+			httputil.PostTypeField:        "raw data",
+			httputil.PostValueField:       map[string]interface{}{"check": "deep"},
+			httputil.PostContentTypeField: "application/json",
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.HttpMethodField:      uint8(4),
+			httputil.PostTypeField:        uint8(2),
+			httputil.PostValueField:       `{"check":"deep"}`,
+			httputil.PostContentTypeField: uint8(1),
+		}},
+		{name: "should resolve http method given in lowercase or by number", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.HttpMethodField: "post",
+			httputil.PostTypeField:   float64(1),
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.HttpMethodField: uint8(3),
+			httputil.PostTypeField:   uint8(1),
+		}},
+		{name: "should fail on invalid custom_http_statuses", setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.CustomHttpStatusesField: "404:2",
+		}}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -684,7 +857,12 @@ func TestMonitorService_DeleteRequest(t *testing.T) {
 		}, args: args{dataMap: map[string]interface{}{}}, wantErr: true},
 		{name: "should not return error when id is present", setupMocks: func() {
 			testmonitorservice = &testMonitorService{}
-			testmonitorservice.On("HttpInitiatePostRequest", mock.Anything, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.MonitorsField: "2"})).Return(map[string]interface{}{
+				"monitors": []interface{}{
+					map[string]interface{}{httputil.IdField: float64(2), httputil.FriendlyNameField: "test"},
+				},
+			}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "2"}).Return(map[string]interface{}{}, nil)
 			monitorservice.IService = testmonitorservice
 		}, verifyMocks: func() {
 			testmonitorservice.AssertExpectations(t)
@@ -693,10 +871,11 @@ func TestMonitorService_DeleteRequest(t *testing.T) {
 		}}, wantErr: false},
 		{name: "should not return error when friendly_name is present", setupMocks: func() {
 			testmonitorservice = &testMonitorService{}
-			testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "3"}).Return(map[string]interface{}{}, nil)
 			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
 				"monitors": []interface{}{
 					map[string]interface{}{
+						httputil.IdField:           float64(3),
 						httputil.FriendlyNameField: "test",
 					},
 				},
@@ -864,3 +1043,77 @@ func TestMonitorService_InitiateRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestMonitorService_HandleRequest_Concurrency(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, Concurrency: 4}
+
+	var data, want []map[string]interface{}
+	for idx := 0; idx < 20; idx++ {
+		name := fmt.Sprintf("monitor-%d", idx)
+		data = append(data, map[string]interface{}{httputil.FriendlyNameField: name, httputil.UrlField: "https://localhost", httputil.TypeField: "HTTP"})
+		want = append(want, map[string]interface{}{model.ErrorResultField: nil, model.MonitorNameResultField: name, model.ActionResultField: model.Created})
+	}
+
+	if got := monitorservice.HandleRequest(context.Background(), data, model.Create); !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleRequest() = %v, want %v", got, want)
+	}
+	testmonitorservice.AssertNumberOfCalls(t, "HttpInitiatePostRequest", 20)
+}
+
+func TestMonitorService_HandleRequest_ContinueOnError(t *testing.T) {
+	data := []map[string]interface{}{
+		{httputil.FriendlyNameField: "invalid", httputil.UrlField: "https://localhost"},
+		{httputil.FriendlyNameField: "valid", httputil.UrlField: "https://localhost", httputil.TypeField: "HTTP"},
+	}
+	tests := []struct {
+		name            string
+		continueOnError bool
+		want            []map[string]interface{}
+	}{
+		{name: "should stop at the first failed monitor", continueOnError: false, want: []map[string]interface{}{
+			{model.ErrorResultField: fmt.Errorf(MsgFieldMissing, httputil.TypeField), model.MonitorNameResultField: "invalid"},
+			nil,
+		}},
+		{name: "should handle the remaining monitors after a failed monitor", continueOnError: true, want: []map[string]interface{}{
+			{model.ErrorResultField: fmt.Errorf(MsgFieldMissing, httputil.TypeField), model.MonitorNameResultField: "invalid"},
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "valid", model.ActionResultField: model.Created},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testmonitorservice := &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+			monitorservice := &MonitorService{IService: testmonitorservice, ContinueOnError: tt.continueOnError}
+
+			var items []map[string]interface{}
+			for _, dataMap := range data {
+				item := make(map[string]interface{})
+				for key, value := range dataMap {
+					item[key] = value
+				}
+				items = append(items, item)
+			}
+			if got := monitorservice.HandleRequest(context.Background(), items, model.Create); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HandleRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorService_forItem(t *testing.T) {
+	monitorservice := &MonitorService{Concurrency: 4, ContinueOnError: true, FuzzyMatch: true, Sources: []string{"monitors.json[0]"}, source: "monitors.json[0]",
+		changes: []model.Change{{Name: "api"}}, actionTaken: model.Updated, remoteId: "1", changedFields: []string{httputil.UrlField}}
+
+	got := monitorservice.forItem(map[string]interface{}{httputil.FriendlyNameField: "api"})
+	if !got.ContinueOnError || !got.FuzzyMatch || !reflect.DeepEqual(got.Sources, monitorservice.Sources) {
+		t.Errorf("forItem() did not copy the settings of the service: %+v", got)
+	}
+	if got.Concurrency != 1 || got.source != "" || got.changes != nil || got.actionTaken != "" || got.remoteId != "" || got.changedFields != nil {
+		t.Errorf("forItem() kept the state of the last item: %+v", got)
+	}
+}
@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	"sort"
+	"strings"
+)
+
+const MonitorDryRunEnv = "MONITOR_DRY_RUN"
+
+const (
+	MsgDryRunSkippedRequest = "dry run: skipped %s request"
+	MsgMonitorUnchanged     = "%s monitor is unchanged, skipping edit"
+)
+
+// Fields getMonitors leaves out unless asked for, they are needed to tell whether an update changes anything.
+var monitorDetailFields = httputil.MonitorDetailFlags
+
+// Endpoints that are never called on a dry run.
+var writeEndpoints = map[string]bool{
+	httputil.NewMonitorEndpoint:    true,
+	httputil.EditMonitorEndpoint:   true,
+	httputil.DeleteMonitorEndpoint: true,
+}
+
+/*
+*
+Records what a write would do instead of performing it, the changes are attached to the result of the item being handled
+(see takeChanges).
+*/
+func (service *MonitorService) recordChange(action string, dataMap map[string]interface{}, remoteMonitor map[string]interface{}) {
+	change := model.Change{Action: action}
+	name := dataMap[httputil.FriendlyNameField]
+	if remoteMonitor != nil {
+		change.Id = serviceutil.ToString(remoteMonitor[httputil.IdField])
+		if name == nil {
+			name = remoteMonitor[httputil.FriendlyNameField]
+		}
+	}
+	if name != nil {
+		change.Name = fmt.Sprint(name)
+	}
+	if action != model.Deleted {
+		change.Fields = redactFieldChanges(diffMonitor(dataMap, remoteMonitor))
+	}
+	service.changes = append(service.changes, change)
+}
+
+// Redacts the secrets in the values of fieldChanges, the plan still shows which fields change (see redactutil.Field).
+func redactFieldChanges(fieldChanges []model.FieldChange) []model.FieldChange {
+	for idx, fieldChange := range fieldChanges {
+		fieldChanges[idx].Remote = fmt.Sprint(redactutil.Field(fieldChange.Field, fieldChange.Remote))
+		fieldChanges[idx].Desired = fmt.Sprint(redactutil.Field(fieldChange.Field, fieldChange.Desired))
+	}
+	return fieldChanges
+}
+
+// Asks getMonitors to include monitorDetailFields in the monitors it returns for body.
+func withMonitorDetails(body map[string]interface{}) map[string]interface{} {
+	for _, field := range monitorDetailFields {
+		body[field] = 1
+	}
+	return body
+}
+
+// Returns the changes recorded since the last call.
+func (service *MonitorService) takeChanges() []model.Change {
+	changes := service.changes
+	service.changes = nil
+	return changes
+}
+
+/*
+*
+Returns the fields of dataMap, after resolveMonitorProperties, whose value differs from remoteMonitor sorted by field.
+Alert contacts and maintenance windows of remoteMonitor are compared in their input shape (see formatRemoteList) and
+custom http headers and statuses as they are encoded (see httputil.EncodeField). The order of list items does not matter.
+*/
+func diffMonitor(dataMap map[string]interface{}, remoteMonitor map[string]interface{}) []model.FieldChange {
+	fields := make([]string, 0, len(dataMap))
+	for field := range dataMap {
+		if field != httputil.IdField {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var fieldChanges []model.FieldChange
+	for _, field := range fields {
+		remote := ""
+		if remoteMonitor != nil && remoteMonitor[field] != nil {
+			remote = formatRemoteValue(field, remoteMonitor[field])
+		}
+		desired := serviceutil.ToString(dataMap[field])
+		if remote != desired && normaliseValue(field, remote) != normaliseValue(field, desired) {
+			fieldChanges = append(fieldChanges, model.FieldChange{Field: field, Remote: remote, Desired: desired})
+		}
+	}
+	return fieldChanges
+}
+
+func formatRemoteValue(field string, value interface{}) string {
+	switch field {
+	case httputil.AlertContactsField, httputil.MWindowsField:
+		return formatRemoteList(field, value)
+	case httputil.CustomHttpHeadersField, httputil.CustomHttpStatusesField:
+		if encoded, err := httputil.EncodeField(field, value); err == nil {
+			return encoded
+		}
+	}
+	return serviceutil.ToString(value)
+}
+
+/*
+*
+Returns value in a form that is equal for equivalent values of field: list items are sorted, an alert contact without
+threshold and recurrence gets the defaults the API sets (0_0) and empty custom http headers are dropped.
+*/
+func normaliseValue(field string, value string) string {
+	delimiter := ""
+	switch field {
+	case httputil.AlertContactsField, httputil.MWindowsField:
+		delimiter = httputil.ListDelimiter
+	case httputil.CustomHttpStatusesField:
+		delimiter = httputil.CustomHttpStatusesDelimiter
+	case httputil.CustomHttpHeadersField:
+		if value == "{}" {
+			return ""
+		}
+		return value
+	default:
+		return value
+	}
+	if value == "" {
+		return value
+	}
+
+	items := strings.Split(value, delimiter)
+	for idx, item := range items {
+		if field == httputil.AlertContactsField && !strings.Contains(item, AlertContactsAttribDelimiter) {
+			items[idx] = strings.Join([]string{item, "0", "0"}, AlertContactsAttribDelimiter)
+		}
+	}
+	sort.Strings(items)
+	return strings.Join(items, delimiter)
+}
@@ -0,0 +1,236 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"reflect"
+	"testing"
+)
+
+func TestMonitorService_HandleRequest_DryRun(t *testing.T) {
+	var testmonitorservice *testMonitorService
+	monitorservice := &MonitorService{DryRun: true}
+
+	type args struct {
+		data   []map[string]interface{}
+		action model.Args
+	}
+	tests := []struct {
+		name        string
+		args        args
+		setupMocks  func()
+		verifyMocks func()
+		want        []map[string]interface{}
+	}{
+		{name: "should report the changed fields of an update without editing", args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField:  "api",
+			httputil.UrlField:           "https://api.localhost/health",
+			httputil.TypeField:          "HTTP",
+			httputil.AlertContactsField: "1_0_5-3",
+		}}, model.Update}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("LookUpEnv", MonitorResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{{
+			model.ErrorResultField:       nil,
+			model.MonitorNameResultField: "api",
+			model.PlanResultField: []model.Change{{Action: model.Updated, Id: "777712827", Name: "api", Fields: []model.FieldChange{
+				{Field: httputil.AlertContactsField, Remote: "1_0_5-2", Desired: "1_0_5-3"},
+				{Field: httputil.UrlField, Remote: "https://api.localhost", Desired: "https://api.localhost/health"},
+			}}},
+		}}},
+		{name: "should redact secrets from the changed fields", args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "api",
+			httputil.HttpPasswordField: "plan-password",
+		}}, model.Update}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("LookUpEnv", MonitorResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{{
+			model.ErrorResultField:       nil,
+			model.MonitorNameResultField: "api",
+			model.PlanResultField: []model.Change{{Action: model.Updated, Id: "777712827", Name: "api", Fields: []model.FieldChange{
+				{Field: httputil.HttpPasswordField, Remote: "", Desired: redactutil.Redacted},
+			}}},
+		}}},
+		{name: "should report an update that changes nothing as unchanged", args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField:  "api",
+			httputil.UrlField:           "https://api.localhost",
+			httputil.AlertContactsField: "2-1_0_5",
+		}}, model.Update}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("LookUpEnv", MonitorResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{{
+			model.ErrorResultField:       nil,
+			model.MonitorNameResultField: "api",
+			model.PlanResultField:        []model.Change{{Action: model.Unchanged, Id: "777712827", Name: "api"}},
+		}}},
+		{name: "should flag a type change as a recreation", args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "api",
+			httputil.UrlField:          "https://api.localhost",
+			httputil.TypeField:         "Ping",
+		}}, model.Update}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("LookUpEnv", MonitorResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+			monitorservice.AllowRecreate = true
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+			monitorservice.AllowRecreate = false
+		}, want: []map[string]interface{}{{
+			model.ErrorResultField:       nil,
+			model.MonitorNameResultField: "api",
+			model.PlanResultField: []model.Change{{Action: model.Recreated, Id: "777712827", Name: "api", Fields: []model.FieldChange{
+				{Field: httputil.TypeField, Remote: "1", Desired: "3"},
+			}}},
+		}}},
+		{name: "should flag that a recreation requires -allow-recreate", args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "api",
+			httputil.UrlField:          "https://api.localhost",
+			httputil.TypeField:         "Ping",
+		}}, model.Update}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("LookUpEnv", MonitorResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{{
+			model.ErrorResultField:       nil,
+			model.MonitorNameResultField: "api",
+			model.PlanResultField: []model.Change{{Action: model.Recreated, Id: "777712827", Name: "api", Fields: []model.FieldChange{
+				{Field: httputil.TypeField, Remote: "1", Desired: "3"},
+			}, Note: MsgRecreateNeedsAllow}},
+		}}},
+		{name: "should report a create without creating", args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "web",
+			httputil.UrlField:          "https://web.localhost",
+			httputil.TypeField:         "HTTP",
+		}}, model.Create}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{{
+			model.ErrorResultField:       nil,
+			model.MonitorNameResultField: "web",
+			model.PlanResultField: []model.Change{{Action: model.Created, Name: "web", Fields: []model.FieldChange{
+				{Field: httputil.FriendlyNameField, Remote: "", Desired: "web"},
+				{Field: httputil.TypeField, Remote: "", Desired: "1"},
+				{Field: httputil.UrlField, Remote: "", Desired: "https://web.localhost"},
+			}}},
+		}}},
+		{name: "should resolve a delete by id without deleting", args: args{[]map[string]interface{}{{
+			httputil.IdField: "777712828",
+		}}, model.Delete}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.MonitorsField: "777712828"})).Return(map[string]interface{}{
+				httputil.MonitorsField: []interface{}{map[string]interface{}{httputil.IdField: float64(777712828), httputil.FriendlyNameField: "api-staging"}},
+			}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{{
+			model.ErrorResultField:       nil,
+			model.MonitorNameResultField: "",
+			model.PlanResultField:        []model.Change{{Action: model.Deleted, Id: "777712828", Name: "api-staging"}},
+		}}},
+		{name: "should return error on a delete of a missing monitor", args: args{[]map[string]interface{}{{
+			httputil.IdField: "1",
+		}}, model.Delete}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.MonitorsField: "1"})).Return(map[string]interface{}{httputil.MonitorsField: []interface{}{}}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{{
+			model.ErrorResultField:       errors.New(MsgMonitorDoesNotExist),
+			model.MonitorNameResultField: "",
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if got := monitorservice.HandleRequest(context.Background(), tt.args.data, tt.args.action); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HandleRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonitorService_InitiateRequest_DryRun(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, DryRun: true}
+
+	for _, endpoint := range []string{httputil.NewMonitorEndpoint, httputil.EditMonitorEndpoint, httputil.DeleteMonitorEndpoint} {
+		if _, err := monitorservice.InitiateRequest(endpoint, map[string]interface{}{httputil.IdField: "1"}); err != nil {
+			t.Errorf("InitiateRequest() error = %v", err)
+		}
+	}
+}
+
+func Test_diffMonitor(t *testing.T) {
+	remoteMonitor := map[string]interface{}{
+		httputil.IdField:       float64(1),
+		httputil.IntervalField: float64(300),
+		httputil.AlertContactsField: []interface{}{
+			map[string]interface{}{httputil.IdField: "1", httputil.ThresholdField: float64(0), httputil.RecurrenceField: float64(5)},
+			map[string]interface{}{httputil.IdField: "2", httputil.ThresholdField: float64(0), httputil.RecurrenceField: float64(0)},
+		},
+		httputil.MWindowsField:           []interface{}{map[string]interface{}{httputil.IdField: float64(10)}, map[string]interface{}{httputil.IdField: float64(11)}},
+		httputil.CustomHttpHeadersField:  map[string]interface{}{"X-Token": "a"},
+		httputil.CustomHttpStatusesField: "404:0_200:1",
+	}
+	tests := []struct {
+		name    string
+		dataMap map[string]interface{}
+		want    []model.FieldChange
+	}{
+		{name: "should ignore the order of list items and default alert contact attributes", dataMap: map[string]interface{}{
+			httputil.IdField:                 "1",
+			httputil.IntervalField:           300,
+			httputil.AlertContactsField:      "2-1_0_5",
+			httputil.MWindowsField:           "11-10",
+			httputil.CustomHttpHeadersField:  `{"X-Token":"a"}`,
+			httputil.CustomHttpStatusesField: "200:1_404:0",
+		}},
+		{name: "should report fields that differ", dataMap: map[string]interface{}{
+			httputil.IntervalField:          60,
+			httputil.AlertContactsField:     "1-2",
+			httputil.CustomHttpHeadersField: "",
+		}, want: []model.FieldChange{
+			{Field: httputil.AlertContactsField, Remote: "1_0_5-2_0_0", Desired: "1-2"},
+			{Field: httputil.CustomHttpHeadersField, Remote: `{"X-Token":"a"}`, Desired: ""},
+			{Field: httputil.IntervalField, Remote: "300", Desired: "60"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffMonitor(tt.dataMap, remoteMonitor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffMonitor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	MonitorAllowRecreateEnv = "MONITOR_ALLOW_RECREATE"
+	MonitorSnapshotDirEnv   = "MONITOR_SNAPSHOT_DIR"
+)
+
+const (
+	MsgRecreateNotAllowed       = "changing the type of monitor %v from %s to %s deletes and recreates it losing its history and id, use -allow-recreate to allow it"
+	MsgMonitorSnapshotStored    = "monitor %v snapshot written to %s"
+	MsgMonitorSnapshotNotStored = "monitor %v snapshot could not be written, not recreating it: %v"
+	MsgMonitorRestored          = "recreating monitor %v failed: %v, the original was restored from %s as id %s"
+	MsgMonitorNotRestored       = "recreating monitor %v failed: %v, restoring the original from %s failed too: %v"
+	MsgStatusPageNotUpdated     = "monitor %v was recreated as id %s but status page %s could not be updated: %v"
+	MsgRecreateNeedsAllow       = "requires -allow-recreate"
+)
+
+/*
+*
+RecreateTimeout is how long deleting, creating and, when that fails, restoring a recreated monitor may take. These steps
+are not stopped when the run is cancelled or times out, as that could leave the monitor deleted.
+*/
+const RecreateTimeout = 5 * time.Minute
+
+// Time format of the snapshot and trash file names, it sorts chronologically and contains no -.
+const fileTimeFormat = "20060102T150405.000000000Z"
+
+// Fields of a remote monitor newMonitor accepts as is, see restorePayload.
+var recreatableFields = []string{
+	httputil.FriendlyNameField, httputil.UrlField, httputil.TypeField, httputil.SubTypeField, httputil.PortField,
+	httputil.KeywordTypeField, httputil.KeywordCaseTypeField, httputil.KeywordValueField, httputil.IntervalField,
+	httputil.TimeoutField, httputil.HttpUsernameField, httputil.HttpPasswordField, httputil.HttpAuthTypeField,
+	httputil.HttpMethodField, httputil.PostTypeField, httputil.PostValueField, httputil.PostContentTypeField,
+}
+
+// monitorSnapshot is the state of a monitor written to disk before it is deleted to be recreated.
+type monitorSnapshot struct {
+	TakenAt time.Time              `json:"taken_at"`
+	Monitor map[string]interface{} `json:"monitor"`
+	// StatusPages are the status pages listing the monitor.
+	StatusPages []statusPage `json:"status_pages,omitempty"`
+}
+
+// statusPage is a status page and the ids of the monitors it lists.
+type statusPage struct {
+	Id       string   `json:"id"`
+	Monitors []string `json:"monitors"`
+}
+
+/*
+*
+Replaces originalMonitor with a monitor created from dataMap, used when the type changes since the API does not allow
+editing it. Since this loses the uptime history and id of the monitor it is only done with AllowRecreate, a dry run without
+it reports the recreation as needing it:
+1. The original monitor and the status pages listing it are written to SnapshotDir, the monitor to TrashDir too when it is set.
+2. Alert contacts and maintenance windows of the original monitor are kept unless dataMap sets them.
+3. The original monitor is deleted and the new one created, the status pages are then updated to list the new id.
+If creating the new monitor fails the original is recreated from the snapshot and added back to its status pages. Once
+the original is deleted the requests ignore the cancellation of the run and are bounded by RecreateTimeout instead.
+*/
+func (service *MonitorService) recreateMonitor(dataMap map[string]interface{}, originalMonitor map[string]interface{}) error {
+	name := originalMonitor[httputil.FriendlyNameField]
+	if service.DryRun {
+		service.recordChange(model.Recreated, dataMap, originalMonitor)
+		if !service.AllowRecreate {
+			service.changes[len(service.changes)-1].Note = MsgRecreateNeedsAllow
+		}
+		return nil
+	}
+	if !service.AllowRecreate {
+		return fmt.Errorf(MsgRecreateNotAllowed, name, serviceutil.ToString(originalMonitor[httputil.TypeField]), serviceutil.ToString(dataMap[httputil.TypeField]))
+	}
+
+	originalId := serviceutil.ToString(originalMonitor[httputil.IdField])
+	statusPages, err := service.statusPagesOf(originalId)
+	if err != nil {
+		return err
+	}
+	snapshot := monitorSnapshot{TakenAt: time.Now().UTC(), Monitor: originalMonitor, StatusPages: statusPages}
+	snapshotPath, err := service.writeSnapshot(snapshot)
+	if err != nil {
+		return fmt.Errorf(MsgMonitorSnapshotNotStored, name, err)
+	}
+	service.logger().Infof(MsgMonitorSnapshotStored, name, snapshotPath)
+	if _, err := service.trashMonitor(originalMonitor); err != nil {
+		return fmt.Errorf(MsgMonitorNotTrashed, name, err)
+	}
+
+	for _, field := range []string{httputil.AlertContactsField, httputil.MWindowsField} {
+		if dataMap[field] == nil && originalMonitor[field] != nil {
+			if carriedOver := formatRemoteList(field, originalMonitor[field]); carriedOver != "" {
+				dataMap[field] = carriedOver
+			}
+		}
+	}
+	delete(dataMap, httputil.IdField)
+
+	ctx, cancel := context.WithTimeout(withoutCancel(service.requestContext()), RecreateTimeout)
+	defer cancel()
+	requestCtx := service.ctx
+	service.ctx = ctx
+	defer func() { service.ctx = requestCtx }()
+
+	if _, err := service.writeMonitor(httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: originalId}, originalMonitor); err != nil {
+		return err
+	}
+
+	resultMap, err := service.writeMonitor(httputil.NewMonitorEndpoint, dataMap, nil)
+	if err != nil {
+		restoredId, restoreErr := service.restoreSnapshot(snapshot)
+		if restoreErr != nil {
+			return fmt.Errorf(MsgMonitorNotRestored, name, err, snapshotPath, restoreErr)
+		}
+		return fmt.Errorf(MsgMonitorRestored, name, err, snapshotPath, restoredId)
+	}
+	newId := monitorIdOf(resultMap)
+	service.recordAction(model.Recreated, newId)
+	return service.replaceOnStatusPages(name, statusPages, originalId, newId)
+}
+
+/*
+*
+Creates the monitor of snapshot again and adds it back to its status pages, returns the id of the restored monitor.
+*/
+func (service *MonitorService) restoreSnapshot(snapshot monitorSnapshot) (string, error) {
+	resultMap, err := service.writeMonitor(httputil.NewMonitorEndpoint, restorePayload(snapshot.Monitor), nil)
+	if err != nil {
+		return "", err
+	}
+	restoredId := monitorIdOf(resultMap)
+	originalId := serviceutil.ToString(snapshot.Monitor[httputil.IdField])
+	return restoredId, service.replaceOnStatusPages(snapshot.Monitor[httputil.FriendlyNameField], snapshot.StatusPages, originalId, restoredId)
+}
+
+// detachedContext carries the values of its parent but is never cancelled, see withoutCancel.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (ctx detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (ctx detachedContext) Done() <-chan struct{}             { return nil }
+func (ctx detachedContext) Err() error                        { return nil }
+func (ctx detachedContext) Value(key interface{}) interface{} { return ctx.parent.Value(key) }
+
+// Returns a context with the values of parent that is not cancelled when parent is, like context.WithoutCancel.
+func withoutCancel(parent context.Context) context.Context {
+	return detachedContext{parent: parent}
+}
+
+// Returns the newMonitor payload recreating remoteMonitor as returned by getMonitors with its details.
+func restorePayload(remoteMonitor map[string]interface{}) map[string]interface{} {
+	payload := make(map[string]interface{})
+	for _, field := range recreatableFields {
+		if value := serviceutil.ToString(remoteMonitor[field]); remoteMonitor[field] != nil && value != "" {
+			payload[field] = value
+		}
+	}
+	for _, field := range []string{httputil.AlertContactsField, httputil.MWindowsField} {
+		if value := formatRemoteList(field, remoteMonitor[field]); value != "" {
+			payload[field] = value
+		}
+	}
+	for _, field := range []string{httputil.CustomHttpHeadersField, httputil.CustomHttpStatusesField} {
+		if value, err := httputil.EncodeField(field, remoteMonitor[field]); err == nil && value != "" && value != "{}" {
+			payload[field] = value
+		}
+	}
+	return payload
+}
+
+// Returns the status pages that list the monitor with monitorId, pages listing all monitors are left out.
+func (service *MonitorService) statusPagesOf(monitorId string) ([]statusPage, error) {
+	remoteStatusPages, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetPSPsEndpoint, httputil.PSPsField, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	var statusPages []statusPage
+	for _, remoteStatusPage := range remoteStatusPages {
+		monitors, ok := remoteStatusPage[httputil.MonitorsField].([]interface{})
+		if !ok {
+			continue
+		}
+		page := statusPage{Id: serviceutil.ToString(remoteStatusPage[httputil.IdField])}
+		listed := false
+		for _, monitor := range monitors {
+			id := serviceutil.ToString(monitor)
+			listed = listed || id == monitorId
+			page.Monitors = append(page.Monitors, id)
+		}
+		if listed {
+			statusPages = append(statusPages, page)
+		}
+	}
+	return statusPages, nil
+}
+
+// Edits statusPages to list the monitor with newId in place of oldId.
+func (service *MonitorService) replaceOnStatusPages(name interface{}, statusPages []statusPage, oldId string, newId string) error {
+	for _, page := range statusPages {
+		monitors := make([]string, len(page.Monitors))
+		for idx, id := range page.Monitors {
+			if id == oldId {
+				id = newId
+			}
+			monitors[idx] = id
+		}
+		_, err := service.InitiateRequest(httputil.EditPSPEndpoint, map[string]interface{}{
+			httputil.IdField:       page.Id,
+			httputil.MonitorsField: strings.Join(monitors, httputil.ListDelimiter),
+		})
+		if err != nil {
+			return fmt.Errorf(MsgStatusPageNotUpdated, name, newId, page.Id, err)
+		}
+	}
+	return nil
+}
+
+/*
+*
+Writes snapshot to SnapshotDir as <id>-<time>.json and returns its path. The file is only readable by the current user
+since the monitor may hold http credentials.
+*/
+func (service *MonitorService) writeSnapshot(snapshot monitorSnapshot) (string, error) {
+	dir, err := stateDir(service.SnapshotDir, "snapshots")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", serviceutil.ToString(snapshot.Monitor[httputil.IdField]), snapshot.TakenAt.Format(fileTimeFormat)))
+	return path, os.WriteFile(path, data, 0600)
+}
+
+/*
+*
+Returns dir or, when it is empty, the directory name in uptimerobot-tooling in the user config directory e.g.
+~/.config/uptimerobot-tooling/snapshots.
+*/
+func stateDir(dir string, name string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "uptimerobot-tooling", name), nil
+}
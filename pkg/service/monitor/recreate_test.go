@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMonitorService_recreateMonitor(t *testing.T) {
+	originalMonitor := getMonitorsResponse[httputil.MonitorsField].([]interface{})[0].(map[string]interface{})
+	getPSPsResponse := map[string]interface{}{
+		httputil.PSPsField: []interface{}{
+			map[string]interface{}{httputil.IdField: float64(5), httputil.MonitorsField: []interface{}{float64(777712827), float64(777712828)}},
+			map[string]interface{}{httputil.IdField: float64(6), httputil.MonitorsField: float64(0)},
+			map[string]interface{}{httputil.IdField: float64(7), httputil.MonitorsField: []interface{}{float64(777712828)}},
+		},
+	}
+	// the payload after resolveMonitorProperties, alert contacts and maintenance windows are carried over
+	newMonitor := func() map[string]interface{} {
+		return map[string]interface{}{httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost", httputil.TypeField: uint8(3)}
+	}
+	newMonitorRequest := map[string]interface{}{
+		httputil.FriendlyNameField:  "api",
+		httputil.UrlField:           "https://api.localhost",
+		httputil.TypeField:          uint8(3),
+		httputil.AlertContactsField: "1_0_5-2",
+		httputil.MWindowsField:      "10",
+	}
+	restoreRequest := map[string]interface{}{
+		httputil.FriendlyNameField:  "api",
+		httputil.UrlField:           "https://api.localhost",
+		httputil.TypeField:          "1",
+		httputil.IntervalField:      "300",
+		httputil.AlertContactsField: "1_0_5-2",
+		httputil.MWindowsField:      "10",
+	}
+
+	tests := []struct {
+		name          string
+		allowRecreate bool
+		setupMocks    func(testmonitorservice *testMonitorService)
+		wantAction    string
+		wantId        string
+		wantSnapshot  bool
+		// wantErr is formatted with the snapshot path
+		wantErr func(snapshotPath string) error
+	}{
+		{name: "should return error unless recreating is allowed", wantErr: func(string) error {
+			return fmt.Errorf(MsgRecreateNotAllowed, "api", "1", "3")
+		}},
+		{name: "should carry over alert contacts, maintenance windows and status pages", allowRecreate: true, setupMocks: func(testmonitorservice *testMonitorService) {
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetPSPsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(getPSPsResponse, nil)
+			trashMocks(testmonitorservice)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "777712827"}).Return(map[string]interface{}{}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, newMonitorRequest).Return(map[string]interface{}{
+				httputil.MonitorField: map[string]interface{}{httputil.IdField: float64(900)},
+			}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.EditPSPEndpoint, map[string]interface{}{httputil.IdField: "5", httputil.MonitorsField: "900-777712828"}).Return(map[string]interface{}{}, nil)
+		}, wantAction: model.Recreated, wantId: "900", wantSnapshot: true},
+		{name: "should restore the original monitor when the new one cannot be created", allowRecreate: true, setupMocks: func(testmonitorservice *testMonitorService) {
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetPSPsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(getPSPsResponse, nil)
+			trashMocks(testmonitorservice)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "777712827"}).Return(map[string]interface{}{}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, newMonitorRequest).Return(nil, errors.New("unknown error"))
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, restoreRequest).Return(map[string]interface{}{
+				httputil.MonitorField: map[string]interface{}{httputil.IdField: float64(901)},
+			}, nil)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.EditPSPEndpoint, map[string]interface{}{httputil.IdField: "5", httputil.MonitorsField: "901-777712828"}).Return(map[string]interface{}{}, nil)
+		}, wantErr: func(snapshotPath string) error {
+			return fmt.Errorf(MsgMonitorRestored, "api", errors.New("unknown error"), snapshotPath, "901")
+		}, wantSnapshot: true},
+		{name: "should not delete the monitor when its status pages cannot be fetched", allowRecreate: true, setupMocks: func(testmonitorservice *testMonitorService) {
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetPSPsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(nil, errors.New("unknown error"))
+		}, wantErr: func(string) error {
+			return errors.New("unknown error")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testmonitorservice := &testMonitorService{}
+			if tt.setupMocks != nil {
+				tt.setupMocks(testmonitorservice)
+			}
+			defer testmonitorservice.AssertExpectations(t)
+			snapshotDir := t.TempDir()
+			monitorservice := &MonitorService{IService: testmonitorservice, AllowRecreate: tt.allowRecreate, SnapshotDir: snapshotDir, TrashDir: t.TempDir()}
+
+			err := monitorservice.updateMonitor(newMonitor(), originalMonitor)
+
+			snapshots, _ := filepath.Glob(filepath.Join(snapshotDir, "777712827-*.json"))
+			snapshotPath := ""
+			if len(snapshots) == 1 {
+				snapshotPath = snapshots[0]
+			}
+			var wantErr error
+			if tt.wantErr != nil {
+				wantErr = tt.wantErr(snapshotPath)
+			}
+			if !reflect.DeepEqual(err, wantErr) {
+				t.Errorf("updateMonitor() error = %v, wantErr %v", err, wantErr)
+			}
+			if monitorservice.actionTaken != tt.wantAction || monitorservice.remoteId != tt.wantId {
+				t.Errorf("updateMonitor() action = %s %s, want %s %s", monitorservice.actionTaken, monitorservice.remoteId, tt.wantAction, tt.wantId)
+			}
+			if (len(snapshots) == 1) != tt.wantSnapshot || len(snapshots) > 1 {
+				t.Fatalf("updateMonitor() wrote snapshots %v, wantSnapshot %v", snapshots, tt.wantSnapshot)
+			}
+			if !tt.wantSnapshot {
+				return
+			}
+			data, err := os.ReadFile(snapshotPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var snapshot monitorSnapshot
+			if err := json.Unmarshal(data, &snapshot); err != nil {
+				t.Fatal(err)
+			}
+			wantStatusPages := []statusPage{{Id: "5", Monitors: []string{"777712827", "777712828"}}}
+			if snapshot.Monitor[httputil.FriendlyNameField] != "api" || !reflect.DeepEqual(snapshot.StatusPages, wantStatusPages) {
+				t.Errorf("updateMonitor() snapshot = %v, want monitor api on status pages %v", snapshot, wantStatusPages)
+			}
+		})
+	}
+}
+
+// cancellableMonitorService fails requests made on a context that is done, like the http client does.
+type cancellableMonitorService struct {
+	*testMonitorService
+}
+
+func (t *cancellableMonitorService) HttpInitiatePostRequest(ctx context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return t.testMonitorService.HttpInitiatePostRequest(ctx, endpoint, dataMap)
+}
+
+func TestMonitorService_recreateMonitor_Cancelled(t *testing.T) {
+	originalMonitor := getMonitorsResponse[httputil.MonitorsField].([]interface{})[0].(map[string]interface{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetPSPsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(map[string]interface{}{
+		httputil.PSPsField: []interface{}{},
+	}, nil)
+	trashMocks(testmonitorservice)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "777712827"}).Return(map[string]interface{}{}, nil).
+		Run(func(mock.Arguments) { cancel() })
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, mock.MatchedBy(func(data map[string]interface{}) bool {
+		return data[httputil.TypeField] == uint8(3)
+	})).Return(nil, errors.New("unknown error"))
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, mock.MatchedBy(func(data map[string]interface{}) bool {
+		return data[httputil.TypeField] == "1"
+	})).Return(map[string]interface{}{
+		httputil.MonitorField: map[string]interface{}{httputil.IdField: float64(901)},
+	}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	snapshotDir := t.TempDir()
+	monitorservice := &MonitorService{IService: &cancellableMonitorService{testmonitorservice}, AllowRecreate: true, SnapshotDir: snapshotDir, TrashDir: t.TempDir()}
+	monitorservice.ctx = ctx
+
+	err := monitorservice.updateMonitor(map[string]interface{}{
+		httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost", httputil.TypeField: uint8(3),
+	}, originalMonitor)
+
+	snapshots, _ := filepath.Glob(filepath.Join(snapshotDir, "777712827-*.json"))
+	if len(snapshots) != 1 {
+		t.Fatalf("updateMonitor() wrote snapshots %v, want one", snapshots)
+	}
+	wantErr := fmt.Errorf(MsgMonitorRestored, "api", errors.New("unknown error"), snapshots[0], "901")
+	if !reflect.DeepEqual(err, wantErr) {
+		t.Errorf("updateMonitor() error = %v, wantErr %v", err, wantErr)
+	}
+	if monitorservice.ctx != ctx {
+		t.Errorf("updateMonitor() left the context of the service replaced")
+	}
+}
@@ -0,0 +1,312 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const MonitorTrashDirEnv = "MONITOR_TRASH_DIR"
+
+const (
+	MsgMonitorTrashed           = "monitor %v written to trash %s"
+	MsgMonitorNotTrashed        = "monitor %v could not be written to the trash, not deleting it: %v"
+	MsgTrashedNamesNotResolved  = "monitor %v alert contacts and maintenance windows are trashed by id: %v"
+	MsgNotInTrash               = "no monitor matching %s is in trash %s"
+	MsgMonitorExists            = "monitor %s already exists (id %s), not restoring it"
+	MsgMonitorRestoredFromTrash = "monitor %s restored from %s"
+	MsgMWindowNotResolved       = "maintenance window %s not resolved"
+	MsgAmbiguousMWindow         = "more than one maintenance window is named %s, use its id"
+	MsgTrashDirMissing          = "no trash to restore from, set %s"
+)
+
+/*
+*
+mWindowNameIndex holds the friendly_names of the maintenance windows in the account by id. It is fetched once by the
+first monitor that needs it and shared by the monitors of a request.
+*/
+type mWindowNameIndex struct {
+	mu     sync.Mutex
+	loaded bool
+	names  map[string]string
+}
+
+// trashEntry is a monitor in the trash, named <id>-<time>.json after the id it had and the time it was deleted.
+type trashEntry struct {
+	path      string
+	id        string
+	deletedAt time.Time
+	monitor   map[string]interface{}
+}
+
+/*
+*
+Writes remoteMonitor, as returned by getMonitors with its details, to TrashDir in the input shape (see normaliseMonitor)
+and returns the path of the file. Alert contacts and maintenance windows are written by friendly_name, where it resolves
+back to the same one, so that RestoreRequest can resolve them again. The file is only readable by the current user
+since the monitor may hold http credentials. Nothing is written when TrashDir is not set.
+*/
+func (service *MonitorService) trashMonitor(remoteMonitor map[string]interface{}) (string, error) {
+	if service.TrashDir == "" {
+		return "", nil
+	}
+	monitor := normaliseMonitor(remoteMonitor)
+	delete(monitor, httputil.IdField)
+	if err := service.nameLists(monitor); err != nil {
+		service.logger().Warnf(MsgTrashedNamesNotResolved, monitor[httputil.FriendlyNameField], err)
+	}
+
+	dir := service.TrashDir
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(monitor, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", serviceutil.ToString(remoteMonitor[httputil.IdField]), time.Now().UTC().Format(fileTimeFormat)))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	service.logger().Infof(MsgMonitorTrashed, monitor[httputil.FriendlyNameField], path)
+	return path, nil
+}
+
+/*
+*
+Recreates the monitor selected by dataMap from the trash, the latest one deleted with the id in dataMap or otherwise
+named friendly_name. Its alert contacts and maintenance windows are resolved by friendly_name again. A monitor that
+still exists is not restored.
+*/
+func (service *MonitorService) RestoreRequest(dataMap map[string]interface{}) error {
+	entry, err := service.findTrashEntry(dataMap)
+	if err != nil {
+		return err
+	}
+	monitor := entry.monitor
+	name := fmt.Sprint(monitor[httputil.FriendlyNameField])
+	if dataMap[httputil.FriendlyNameField] == nil {
+		// the result is named after the monitor
+		dataMap[httputil.FriendlyNameField] = name
+	}
+
+	existing, err := service.findMonitorByFriendlyName(name, false, false)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf(MsgMonitorExists, name, serviceutil.ToString(existing[httputil.IdField]))
+	}
+
+	if err := service.resolveLists(monitor); err != nil {
+		return err
+	}
+	if err := service.isValidPayload(monitor, model.Create); err != nil {
+		return err
+	}
+	if err := service.resolveMonitorProperties(monitor); err != nil {
+		return err
+	}
+	if err := service.CreateRequest(monitor); err != nil {
+		return err
+	}
+	if !service.DryRun {
+		service.recordAction(model.Restored, service.remoteId)
+		service.logger().Infof(MsgMonitorRestoredFromTrash, name, entry.path)
+	}
+	return nil
+}
+
+// Returns the latest trash entry of the monitor that had the id in dataMap or, without an id, named friendly_name.
+func (service *MonitorService) findTrashEntry(dataMap map[string]interface{}) (*trashEntry, error) {
+	var selector string
+	if dataMap[httputil.IdField] != nil {
+		selector = serviceutil.ToString(dataMap[httputil.IdField])
+	} else if dataMap[httputil.FriendlyNameField] != nil {
+		selector = fmt.Sprint(dataMap[httputil.FriendlyNameField])
+	} else {
+		return nil, fmt.Errorf(MsgFieldMissing, "id or friendly_name")
+	}
+
+	dir := service.TrashDir
+	if dir == "" {
+		return nil, fmt.Errorf(MsgTrashDirMissing, MonitorTrashDirEnv)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var latest *trashEntry
+	for _, path := range paths {
+		parts := strings.SplitN(strings.TrimSuffix(filepath.Base(path), ".json"), "-", 2)
+		deletedAt, err := time.Parse(fileTimeFormat, parts[1])
+		if err != nil {
+			continue
+		}
+		if dataMap[httputil.IdField] != nil && parts[0] != selector {
+			continue
+		}
+		if latest != nil && !deletedAt.After(latest.deletedAt) {
+			continue
+		}
+		monitor, err := readTrashedMonitor(path)
+		if err != nil {
+			return nil, err
+		}
+		if dataMap[httputil.IdField] == nil && fmt.Sprint(monitor[httputil.FriendlyNameField]) != selector {
+			continue
+		}
+		latest = &trashEntry{path: path, id: parts[0], deletedAt: deletedAt, monitor: monitor}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf(MsgNotInTrash, selector, dir)
+	}
+	return latest, nil
+}
+
+func readTrashedMonitor(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var monitor map[string]interface{}
+	err = json.Unmarshal(data, &monitor)
+	return monitor, err
+}
+
+// Replaces the alert contact and maintenance window ids of monitor, in the input shape, with their friendly_names.
+func (service *MonitorService) nameLists(monitor map[string]interface{}) error {
+	if alertContacts, ok := monitor[httputil.AlertContactsField].(string); ok && alertContacts != "" {
+		index, err := service.alertContactIndex()
+		if err != nil {
+			return err
+		}
+		// ids without an exportable name are kept so the mapping never fails
+		monitor[httputil.AlertContactsField], _ = mapList(alertContacts, func(id string) (string, error) {
+			if name, exists := index.name(id); exists && isResolvableName(name) {
+				return name, nil
+			}
+			return id, nil
+		})
+	}
+	if mWindows, ok := monitor[httputil.MWindowsField].(string); ok && mWindows != "" {
+		names, err := service.fetchMWindowNames()
+		if err != nil {
+			return err
+		}
+		monitor[httputil.MWindowsField], _ = mapList(mWindows, func(id string) (string, error) {
+			if name, exists := names[id]; exists && isResolvableName(name) {
+				return name, nil
+			}
+			return id, nil
+		})
+	}
+	return nil
+}
+
+// Replaces the alert contact and maintenance window friendly_names of monitor, in the input shape, with their ids.
+func (service *MonitorService) resolveLists(monitor map[string]interface{}) error {
+	if alertContacts, ok := monitor[httputil.AlertContactsField].(string); ok && alertContacts != "" {
+		index, err := service.alertContactIndex()
+		if err != nil {
+			return err
+		}
+		resolved, err := mapList(alertContacts, func(name string) (string, error) {
+			return service.resolveAlertContact(index, name)
+		})
+		if err != nil {
+			return err
+		}
+		monitor[httputil.AlertContactsField] = resolved
+	}
+	if mWindows, ok := monitor[httputil.MWindowsField].(string); ok && mWindows != "" {
+		names, err := service.fetchMWindowNames()
+		if err != nil {
+			return err
+		}
+		resolved, err := mapList(mWindows, func(name string) (string, error) {
+			return resolveMWindow(names, name)
+		})
+		if err != nil {
+			return err
+		}
+		monitor[httputil.MWindowsField] = resolved
+	}
+	return nil
+}
+
+/*
+*
+Applies mapId to the id, or name, of every item of list joined by the default delimiters, the attributes of alert
+contacts are kept.
+*/
+func mapList(list string, mapId func(id string) (string, error)) (string, error) {
+	items := strings.Split(list, AlertContactsDelimiter)
+	for idx, item := range items {
+		attribs := strings.Split(item, AlertContactsAttribDelimiter)
+		mapped, err := mapId(attribs[0])
+		if err != nil {
+			return "", err
+		}
+		attribs[0] = mapped
+		items[idx] = strings.Join(attribs, AlertContactsAttribDelimiter)
+	}
+	return strings.Join(items, AlertContactsDelimiter), nil
+}
+
+/*
+*
+Returns the friendly_names of the maintenance windows in the account by id, fetched once per request (see
+mWindowNameIndex). Outside a request they are fetched on every call.
+*/
+func (service *MonitorService) fetchMWindowNames() (map[string]string, error) {
+	index := service.mWindowNames
+	if index == nil {
+		index = &mWindowNameIndex{}
+	}
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	if index.loaded {
+		return index.names, nil
+	}
+
+	mWindows, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetMWindowsEndpoint, httputil.MWindowsField, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	index.names = make(map[string]string, len(mWindows))
+	for _, mWindow := range mWindows {
+		if mWindow[httputil.IdField] != nil && mWindow[httputil.FriendlyNameField] != nil {
+			index.names[serviceutil.ToString(mWindow[httputil.IdField])] = fmt.Sprint(mWindow[httputil.FriendlyNameField])
+		}
+	}
+	index.loaded = true
+	return index.names, nil
+}
+
+// Returns the id of the maintenance window named name in names, a numeric name that matches none is taken to be an id.
+func resolveMWindow(names map[string]string, name string) (string, error) {
+	var ids []string
+	for id, mWindowName := range names {
+		if mWindowName == name {
+			ids = append(ids, id)
+		}
+	}
+	switch {
+	case len(ids) > 1:
+		return "", fmt.Errorf(MsgAmbiguousMWindow, name)
+	case len(ids) == 1:
+		return ids[0], nil
+	}
+	if _, err := serviceutil.ToInt(name); err != nil {
+		return "", fmt.Errorf(MsgMWindowNotResolved, name)
+	}
+	return name, nil
+}
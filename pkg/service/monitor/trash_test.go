@@ -0,0 +1,217 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Mocks the lookups of the alert contact and maintenance window names of the monitors in getMonitorsResponse.
+func trashMocks(testmonitorservice *testMonitorService) {
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(alertContactsPage(0, 50, 2), nil)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(map[string]interface{}{
+		httputil.MWindowsField: []interface{}{
+			map[string]interface{}{httputil.IdField: float64(10), httputil.FriendlyNameField: "weekly"},
+			map[string]interface{}{httputil.IdField: float64(11), httputil.FriendlyNameField: "nightly"},
+		},
+	}, nil)
+}
+
+func TestMonitorService_DeleteRequest_Trash(t *testing.T) {
+	trashDir := t.TempDir()
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("LookUpEnv", MonitorResolveByFriendlyNameEnv).Return("", false)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+	trashMocks(testmonitorservice)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "777712827"}).Return(map[string]interface{}{}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, TrashDir: trashDir}
+
+	if err := monitorservice.DeleteRequest(map[string]interface{}{httputil.FriendlyNameField: "api"}); err != nil {
+		t.Fatalf("DeleteRequest() error = %v", err)
+	}
+	paths, _ := filepath.Glob(filepath.Join(trashDir, "777712827-*.json"))
+	if len(paths) != 1 {
+		t.Fatalf("DeleteRequest() trashed %v, want one file", paths)
+	}
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		httputil.FriendlyNameField:  "api",
+		httputil.UrlField:           "https://api.localhost",
+		httputil.TypeField:          "HTTP",
+		httputil.IntervalField:      float64(300),
+		httputil.AlertContactsField: "contact 1_0_5-contact 2",
+		httputil.MWindowsField:      "weekly",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeleteRequest() trashed %v, want %v", got, want)
+	}
+}
+
+func TestMonitorService_HandleRequest_TrashNamesFetchedOnce(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("LookUpEnv", MonitorResolveByFriendlyNameEnv).Return("", false)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, withMonitorDetails(map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0})).Return(getMonitorsResponse, nil)
+	trashMocks(testmonitorservice)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "777712827"}).Return(map[string]interface{}{}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, TrashDir: t.TempDir()}
+
+	monitorservice.HandleRequest(context.Background(), []map[string]interface{}{{httputil.FriendlyNameField: "api"}, {httputil.FriendlyNameField: "api"}}, model.Delete)
+	testmonitorservice.AssertNumberOfCalls(t, "HttpInitiatePostRequest", 6)
+}
+
+func TestMonitorService_DeleteRequest_NoTrash(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+		httputil.MonitorsField: []interface{}{map[string]interface{}{httputil.IdField: float64(2), httputil.FriendlyNameField: "test"}},
+	}, nil)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, map[string]interface{}{httputil.IdField: "2"}).Return(map[string]interface{}{}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice}
+
+	if err := monitorservice.DeleteRequest(map[string]interface{}{httputil.IdField: "2"}); err != nil {
+		t.Errorf("DeleteRequest() error = %v", err)
+	}
+}
+
+func TestMonitorService_DeleteRequest_NotTrashed(t *testing.T) {
+	// a file where the trash directory should be
+	trashDir := filepath.Join(t.TempDir(), "trash")
+	if err := os.WriteFile(trashDir, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+		httputil.MonitorsField: []interface{}{map[string]interface{}{httputil.IdField: float64(2), httputil.FriendlyNameField: "test"}},
+	}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice, TrashDir: trashDir}
+
+	if err := monitorservice.DeleteRequest(map[string]interface{}{httputil.IdField: "2"}); err == nil {
+		t.Errorf("DeleteRequest() error = nil, want an error")
+	}
+	testmonitorservice.AssertNotCalled(t, "HttpInitiatePostRequest", httputil.DeleteMonitorEndpoint, mock.Anything)
+}
+
+func TestMonitorService_HandleRequest_Restore(t *testing.T) {
+	var testmonitorservice *testMonitorService
+	trashDir := t.TempDir()
+	writeTrashed := func(name string, monitor map[string]interface{}) {
+		data, _ := json.Marshal(monitor)
+		if err := os.WriteFile(filepath.Join(trashDir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTrashed("777712827-20240101T000000.000000000Z.json", map[string]interface{}{
+		httputil.FriendlyNameField: "api", httputil.UrlField: "https://old.api.localhost", httputil.TypeField: "HTTP",
+	})
+	writeTrashed("777712827-20240102T000000.000000000Z.json", map[string]interface{}{
+		httputil.FriendlyNameField: "api", httputil.UrlField: "https://api.localhost", httputil.TypeField: "HTTP",
+		httputil.AlertContactsField: "contact 1_0_5-contact 2", httputil.MWindowsField: "weekly-11",
+	})
+	monitorservice := &MonitorService{TrashDir: trashDir}
+
+	tests := []struct {
+		name        string
+		args        []map[string]interface{}
+		setupMocks  func()
+		verifyMocks func()
+		want        []map[string]interface{}
+	}{
+		{name: "should recreate the latest trashed monitor resolving names", args: []map[string]interface{}{{httputil.FriendlyNameField: "api"}}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0}).Return(map[string]interface{}{httputil.MonitorsField: []interface{}{}}, nil)
+			trashMocks(testmonitorservice)
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, map[string]interface{}{
+				httputil.FriendlyNameField:  "api",
+				httputil.UrlField:           "https://api.localhost",
+				httputil.TypeField:          uint8(1),
+				httputil.AlertContactsField: "1_0_5-2",
+				httputil.MWindowsField:      "10-11",
+			}).Return(map[string]interface{}{httputil.MonitorField: map[string]interface{}{httputil.IdField: float64(900)}}, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: nil, model.MonitorNameResultField: "api", model.ActionResultField: model.Restored, model.IdResultField: "900"},
+		}},
+		{name: "should not restore a monitor that exists", args: []map[string]interface{}{{httputil.IdField: "777712827"}}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, map[string]interface{}{httputil.SearchField: "api", httputil.OffsetField: 0}).Return(getMonitorsResponse, nil)
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: fmt.Errorf(MsgMonitorExists, "api", "777712827"), model.MonitorNameResultField: "api"},
+		}},
+		{name: "should return error when there is no trash", args: []map[string]interface{}{{httputil.FriendlyNameField: "api"}}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			monitorservice.IService = testmonitorservice
+			monitorservice.TrashDir = ""
+		}, verifyMocks: func() {
+			monitorservice.TrashDir = trashDir
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: fmt.Errorf(MsgTrashDirMissing, MonitorTrashDirEnv), model.MonitorNameResultField: "api"},
+		}},
+		{name: "should return error when the monitor is not in the trash", args: []map[string]interface{}{{httputil.FriendlyNameField: "web"}}, setupMocks: func() {
+			testmonitorservice = &testMonitorService{}
+			monitorservice.IService = testmonitorservice
+		}, verifyMocks: func() {
+			testmonitorservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{
+			{model.ErrorResultField: fmt.Errorf(MsgNotInTrash, "web", trashDir), model.MonitorNameResultField: "web"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if got := monitorservice.HandleRequest(context.Background(), tt.args, model.Restore); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HandleRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveMWindow(t *testing.T) {
+	names := map[string]string{"10": "weekly", "11": "nightly", "12": "nightly"}
+	tests := []struct {
+		name    string
+		want    string
+		wantErr error
+	}{
+		{name: "weekly", want: "10"},
+		{name: "13", want: "13"},
+		{name: "nightly", wantErr: fmt.Errorf(MsgAmbiguousMWindow, "nightly")},
+		{name: "monthly", wantErr: errors.New(fmt.Sprintf(MsgMWindowNotResolved, "monthly"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveMWindow(names, tt.name)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("resolveMWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveMWindow() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+)
+
+/*
+*
+Handles monitors like HandleRequest does for maps, for callers working on model.MonitorConfig. This is a typed wrapper,
+the monitors are converted to the maps HandleRequest works on and their enums are resolved as typed values rather than by
+their names (see model.ToMap). Supports create, update, delete and apply,
+results are in the order of monitors followed by the pruned monitors on apply (see model.ItemResults).
+Use ListMonitors and ExportMonitors to read monitors.
+*/
+func (service *MonitorService) HandleMonitors(ctx context.Context, monitors []model.MonitorConfig, action model.Args) ([]model.ItemResult, error) {
+	if action != model.Create && action != model.Update && action != model.Delete && action != model.Apply {
+		return nil, fmt.Errorf(MsgActionNotSupported, action)
+	}
+	dataMaps := make([]map[string]interface{}, len(monitors))
+	for idx, monitor := range monitors {
+		dataMap, err := monitor.ToMap()
+		if err != nil {
+			return nil, err
+		}
+		dataMaps[idx] = dataMap
+	}
+	return model.ItemResults(model.Monitor, service.HandleRequest(ctx, dataMaps, action)), nil
+}
+
+// Returns the monitors matching filter, see ListRequest. An exact match on friendly_name or id is a get, credentials are
+// masked unless ShowSecrets is set.
+func (service *MonitorService) ListMonitors(ctx context.Context, filter model.MonitorConfig, exact bool) ([]model.MonitorConfig, error) {
+	service.ctx = ctx
+	filterMap, err := filter.ToMap()
+	if err != nil {
+		return nil, err
+	}
+	monitors, err := service.ListRequest(filterMap, exact)
+	if err != nil {
+		return nil, err
+	}
+	service.maskCredentials(monitors)
+	return monitorConfigs(monitors)
+}
+
+// Returns every monitor in the account without ids, see ExportRequest.
+func (service *MonitorService) ExportMonitors(ctx context.Context) ([]model.MonitorConfig, error) {
+	service.ctx = ctx
+	monitors, err := service.ExportRequest()
+	if err != nil {
+		return nil, err
+	}
+	return monitorConfigs(monitors)
+}
+
+// Converts monitors in the input shape, the custom http headers are decoded since normaliseMonitor encodes them.
+func monitorConfigs(monitors []map[string]interface{}) ([]model.MonitorConfig, error) {
+	monitorConfigs := make([]model.MonitorConfig, len(monitors))
+	for idx, monitor := range monitors {
+		if headers, ok := monitor[httputil.CustomHttpHeadersField].(string); ok {
+			var decoded map[string]string
+			if err := json.Unmarshal([]byte(headers), &decoded); err != nil {
+				return nil, err
+			}
+			monitor[httputil.CustomHttpHeadersField] = decoded
+		}
+		if err := model.FromMap(monitor, &monitorConfigs[idx]); err != nil {
+			return nil, err
+		}
+	}
+	return monitorConfigs, nil
+}
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"reflect"
+	"testing"
+)
+
+func TestMonitorService_HandleMonitors(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("LookUpEnv", MonitorAlertContactsResolveByFriendlyNameEnv).Return("", false)
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.NewMonitorEndpoint, map[string]interface{}{
+		httputil.FriendlyNameField:      "api",
+		httputil.UrlField:               "https://api.localhost",
+		httputil.TypeField:              uint8(2),
+		httputil.KeywordTypeField:       uint8(2),
+		httputil.KeywordCaseTypeField:   uint8(0),
+		httputil.KeywordValueField:      "error",
+		httputil.IntervalField:          float64(1000000),
+		httputil.CustomHttpHeadersField: `{"Accept":"text/html"}`,
+	}).Return(map[string]interface{}{httputil.MonitorField: map[string]interface{}{httputil.IdField: float64(1000000)}}, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice}
+
+	caseSensitive := model.KeywordCaseSensitive
+	got, err := monitorservice.HandleMonitors(context.Background(), []model.MonitorConfig{{
+		FriendlyName:      "api",
+		Url:               "https://api.localhost",
+		Type:              model.MonitorTypeKeyword,
+		KeywordType:       model.KeywordNotExists,
+		KeywordCaseType:   &caseSensitive,
+		KeywordValue:      "error",
+		Interval:          1000000,
+		CustomHttpHeaders: map[string]string{"Accept": "text/html"},
+	}}, model.Create)
+	if err != nil {
+		t.Fatalf("HandleMonitors() error = %v", err)
+	}
+	want := []model.ItemResult{{Name: "api", Action: model.Created, Id: "1000000"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleMonitors() = %v, want %v", got, want)
+	}
+
+	if _, err := monitorservice.HandleMonitors(context.Background(), nil, model.List); err == nil {
+		t.Errorf("HandleMonitors() error = nil, want %v", fmt.Errorf(MsgActionNotSupported, model.List))
+	}
+}
+
+func TestMonitorService_ListMonitors(t *testing.T) {
+	testmonitorservice := &testMonitorService{}
+	testmonitorservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(getMonitorsResponse, nil)
+	defer testmonitorservice.AssertExpectations(t)
+	monitorservice := &MonitorService{IService: testmonitorservice}
+
+	got, err := monitorservice.ListMonitors(context.Background(), model.MonitorConfig{FriendlyName: "api-staging"}, true)
+	if err != nil {
+		t.Fatalf("ListMonitors() error = %v", err)
+	}
+	caseInsensitive := model.KeywordCaseInsensitive
+	want := []model.MonitorConfig{{
+		Id:              777712828,
+		FriendlyName:    "api-staging",
+		Url:             "https://staging.api.localhost",
+		Type:            model.MonitorTypeKeyword,
+		KeywordType:     model.KeywordNotExists,
+		KeywordCaseType: &caseInsensitive,
+		KeywordValue:    "error",
+		Interval:        60,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListMonitors() = %v, want %v", got, want)
+	}
+}
+
+func Test_monitorConfigs(t *testing.T) {
+	got, err := monitorConfigs([]map[string]interface{}{{
+		httputil.FriendlyNameField:       "web",
+		httputil.CustomHttpHeadersField:  `{"Authorization":"[REDACTED]"}`,
+		httputil.CustomHttpStatusesField: "404:1",
+	}})
+	if err != nil {
+		t.Fatalf("monitorConfigs() error = %v", err)
+	}
+	want := []model.MonitorConfig{{
+		FriendlyName:       "web",
+		CustomHttpHeaders:  map[string]string{"Authorization": "[REDACTED]"},
+		CustomHttpStatuses: "404:1",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("monitorConfigs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package mwindow
+
+import (
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+)
+
+// Fields of a remote maintenance window that are kept on export.
+var exportFields = []string{httputil.FriendlyNameField, httputil.ValueField, httputil.StartTimeField, httputil.DurationField}
+
+/*
+*
+Returns every maintenance window in the account with its type mapped back to its name so that it can be applied to this
+or another account.
+*/
+func (service *MWindowService) ExportRequest() ([]map[string]interface{}, error) {
+	remoteMWindows, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetMWindowsEndpoint, httputil.MWindowsField, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	mWindows := make([]map[string]interface{}, 0, len(remoteMWindows))
+	for _, remoteMWindow := range remoteMWindows {
+		mWindow := make(map[string]interface{})
+		for _, field := range exportFields {
+			if value, exists := remoteMWindow[field]; exists && value != nil && value != "" {
+				mWindow[field] = value
+			}
+		}
+		if id, err := serviceutil.ToInt(remoteMWindow[httputil.TypeField]); err == nil {
+			if name, exists := model.MWindowEnums[httputil.TypeField].Name(id); exists {
+				mWindow[httputil.TypeField] = name
+			}
+		}
+		mWindows = append(mWindows, mWindow)
+	}
+	return mWindows, nil
+}
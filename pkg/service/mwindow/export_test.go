@@ -0,0 +1,29 @@
+package mwindow
+
+import (
+	"context"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"reflect"
+	"testing"
+)
+
+func TestMWindowService_HandleRequest_Export(t *testing.T) {
+	testmwindowservice := &testMWindowService{}
+	testmwindowservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(mWindowsResponse, nil)
+	defer testmwindowservice.AssertExpectations(t)
+	mwindowservice := &MWindowService{IService: testmwindowservice}
+
+	got := mwindowservice.HandleRequest(context.Background(), []map[string]interface{}{{}}, model.Export)
+	want := []map[string]interface{}{{
+		model.ErrorResultField:       nil,
+		model.MWindowNameResultField: "",
+		model.DataResultField: []map[string]interface{}{
+			{httputil.FriendlyNameField: "deploy", httputil.TypeField: "weekly", httputil.ValueField: "2-4", httputil.StartTimeField: "22:00", httputil.DurationField: float64(30)},
+			{httputil.FriendlyNameField: "migration", httputil.TypeField: "once", httputil.StartTimeField: float64(1672531200), httputil.DurationField: float64(60)},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandleRequest() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,404 @@
+package mwindow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/provider"
+	"github.com/onaio/uptimerobot-tooling/pkg/service"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	log "github.com/sirupsen/logrus"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TypeOnce    = uint8(model.MWindowOnce)
+	TypeDaily   = uint8(model.MWindowDaily)
+	TypeWeekly  = uint8(model.MWindowWeekly)
+	TypeMonthly = uint8(model.MWindowMonthly)
+)
+
+// Layouts accepted for the start_time of a once maintenance window in addition to a unix timestamp.
+var onceStartTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+var recurringStartTimeRegex = regexp.MustCompile(`^([01]?\d|2[0-3]):[0-5]\d$`)
+
+const (
+	MWindowResolveByFriendlyNameEnv = "MWINDOW_RESOLVE_BY_FRIENDLY_NAME"
+)
+
+const (
+	MsgMWindowDoesNotExist                    = "maintenance window does not exist"
+	MsgFieldMissing                           = "%s needs to be specified"
+	MsgFieldIsInvalid                         = "%s field invalid: %s"
+	MsgMappingNotFound                        = "mapping for %s -> %s not found"
+	MsgActionNotSupported                     = "%s action is not supported"
+	MsgOriginalAndProvidedMWindowConflictType = "original and provided maintenance window have conflicting types (orig: %v and provided: %v), the type of a maintenance window cannot be edited"
+	MsgAmbiguousMWindow                       = "more than one maintenance window is named %s (ids %s), specify the id"
+)
+
+func (service *MWindowService) HandleRequest(ctx context.Context, dataMapInterface []map[string]interface{}, action model.Args) []map[string]interface{} {
+	service.ctx = ctx
+	if action == model.Export {
+		mWindows, err := service.ExportRequest()
+		resultArrayMap := createResultObject(model.Result{ErrorResultField: err}, 0, []map[string]interface{}{{}})
+		if err == nil {
+			resultArrayMap[0][model.DataResultField] = mWindows
+		}
+		return resultArrayMap
+	}
+
+	resultArrayMap := make([]map[string]interface{}, len(dataMapInterface))
+	for idx, dataMap := range dataMapInterface {
+		if ctx.Err() != nil {
+			// cancelled, the remaining items are left unhandled
+			break
+		}
+		resultArrayMap[idx] = make(map[string]interface{})
+		if action == model.Create || action == model.Update {
+			if err := service.isValidPayload(dataMap, action); err != nil {
+				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+				return resultArrayMap
+			}
+
+			if err := service.resolveMWindowProperties(dataMap); err != nil {
+				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+				return resultArrayMap
+			}
+
+			if action == model.Update {
+				if err := service.UpdateRequest(dataMap); err != nil {
+					resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+					return resultArrayMap
+				}
+			} else {
+				if err := service.CreateRequest(dataMap); err != nil {
+					resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+					return resultArrayMap
+				}
+			}
+			resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+		} else if action == model.Delete {
+			log.Infof("deleting %v maintenance window", dataMap[httputil.FriendlyNameField])
+
+			if err := service.DeleteRequest(dataMap); err != nil {
+				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+				return resultArrayMap
+			}
+			resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+
+		} else {
+			log.Fatalf(MsgActionNotSupported, action)
+		}
+	}
+
+	return resultArrayMap
+}
+
+/*
+*
+Populates resultMap on an index with error and maintenance window name.
+*/
+func createResultObject(result model.Result, index int, resultArrayMap []map[string]interface{}) []map[string]interface{} {
+	resultArrayMap[index][model.ErrorResultField] = result.ErrorResultField
+	if result.NameResultField != nil {
+		resultArrayMap[index][model.MWindowNameResultField] = fmt.Sprint(result.NameResultField)
+	} else {
+		resultArrayMap[index][model.MWindowNameResultField] = ""
+	}
+	return resultArrayMap
+}
+
+/*
+*
+Checks if the payload supplied is valid i.e.
+On update: friendly_name or id needs to be preset.
+On creation: friendly_name, type, start_time and duration need to be specified; weekly and monthly windows also need value.
+*/
+func (service *MWindowService) isValidPayload(dataMap map[string]interface{}, args model.Args) error {
+	if args == model.Update {
+		if dataMap[httputil.FriendlyNameField] == nil && dataMap[httputil.IdField] == nil {
+			return fmt.Errorf("%s or %s is needed for an update/create", httputil.FriendlyNameField, httputil.IdField)
+		}
+		return nil
+	}
+
+	for _, field := range []string{httputil.FriendlyNameField, httputil.TypeField, httputil.StartTimeField, httputil.DurationField} {
+		if dataMap[field] == nil {
+			return fmt.Errorf(MsgFieldMissing, field)
+		}
+	}
+	mWindowType, err := resolveProperty(httputil.TypeField, dataMap[httputil.TypeField])
+	if err != nil {
+		return err
+	}
+	if (mWindowType == TypeWeekly || mWindowType == TypeMonthly) && dataMap[httputil.ValueField] == nil {
+		return fmt.Errorf("%v maintenance windows require %s", dataMap[httputil.TypeField], httputil.ValueField)
+	}
+	return nil
+}
+
+/*
+*
+Resolves maintenance window properties mapped on model.MWindowEnums e.g. weekly -> 3. Type names are matched
+case-insensitively, the ids they map to are accepted as is.
+*/
+func (service *MWindowService) resolveMWindowProperties(dataMap map[string]interface{}) error {
+	for property, value := range dataMap {
+		if _, exists := model.MWindowEnums[property]; exists {
+			resolved, err := resolveProperty(property, value)
+			if err != nil {
+				return err
+			}
+			dataMap[property] = resolved
+		}
+	}
+	return nil
+}
+
+// Returns the id value of property maps to on model.MWindowEnums, value being either a name or one of the ids.
+func resolveProperty(property string, value interface{}) (uint8, error) {
+	if id, resolved := model.MWindowEnums[property].Resolve(value); resolved {
+		return uint8(id), nil
+	}
+	return 0, fmt.Errorf(MsgMappingNotFound, property, fmt.Sprint(value))
+}
+
+/*
+*
+Validates and normalises start_time, value and duration for a maintenance window of type mWindowType:
+once windows take a unix timestamp (or a date in onceStartTimeLayouts) and other windows take HH:mm, weekly values are
+days of the week 1-7, monthly values are days of the month 1-31 or -1 for the last day and duration is in minutes.
+Values supplied as arrays are joined with "-".
+*/
+func validateSchedule(dataMap map[string]interface{}, mWindowType uint8) error {
+	if startTime, exists := dataMap[httputil.StartTimeField]; exists {
+		if mWindowType == TypeOnce {
+			timestamp, err := parseOnceStartTime(startTime)
+			if err != nil {
+				return err
+			}
+			dataMap[httputil.StartTimeField] = timestamp
+		} else if !recurringStartTimeRegex.MatchString(fmt.Sprint(startTime)) {
+			return fmt.Errorf(MsgFieldIsInvalid, httputil.StartTimeField, "expected HH:mm")
+		}
+	}
+
+	if duration, exists := dataMap[httputil.DurationField]; exists {
+		minutes, err := serviceutil.ToInt(duration)
+		if err != nil || minutes < 1 {
+			return fmt.Errorf(MsgFieldIsInvalid, httputil.DurationField, "expected a positive number of minutes")
+		}
+		dataMap[httputil.DurationField] = minutes
+	}
+
+	if value, exists := dataMap[httputil.ValueField]; exists && (mWindowType == TypeWeekly || mWindowType == TypeMonthly) {
+		var days []string
+		if valueArr, ok := value.([]interface{}); ok {
+			for _, day := range valueArr {
+				days = append(days, fmt.Sprint(day))
+			}
+		} else {
+			days = strings.Split(fmt.Sprint(value), "-")
+		}
+		for index, day := range days {
+			// "-1" is split into an empty string and "1" when the value is a delimited string
+			if day == "" && mWindowType == TypeMonthly && index+1 < len(days) && days[index+1] == "1" {
+				continue
+			}
+			dayNumber, err := strconv.Atoi(day)
+			if day == "" || err != nil {
+				return fmt.Errorf(MsgFieldIsInvalid, httputil.ValueField, day)
+			}
+			if mWindowType == TypeWeekly && (dayNumber < 1 || dayNumber > 7) {
+				return fmt.Errorf(MsgFieldIsInvalid, httputil.ValueField, "weekly days need to be between 1 and 7")
+			}
+			if mWindowType == TypeMonthly && (dayNumber < -1 || dayNumber == 0 || dayNumber > 31) {
+				return fmt.Errorf(MsgFieldIsInvalid, httputil.ValueField, "monthly days need to be between 1 and 31 or -1")
+			}
+		}
+		if _, ok := value.([]interface{}); ok {
+			dataMap[httputil.ValueField] = strings.Join(days, "-")
+		}
+	}
+	return nil
+}
+
+func parseOnceStartTime(startTime interface{}) (int64, error) {
+	if timestamp, err := serviceutil.ToInt(startTime); err == nil {
+		return int64(timestamp), nil
+	}
+	for _, layout := range onceStartTimeLayouts {
+		if parsed, err := time.Parse(layout, fmt.Sprint(startTime)); err == nil {
+			return parsed.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf(MsgFieldIsInvalid, httputil.StartTimeField, "expected a unix timestamp or a date e.g. 2006-01-02 15:04")
+}
+
+func (service *MWindowService) UpdateRequest(dataMap map[string]interface{}) error {
+	originalMWindow, err := service.findMWindow(dataMap)
+	if err != nil {
+		return err
+	}
+
+	if originalMWindow != nil {
+		log.Infof("%s %s %s", "updating", dataMap[httputil.FriendlyNameField], "maintenance window")
+		dataMap[httputil.IdField] = serviceutil.ToString(originalMWindow[httputil.IdField])
+
+		if dataMap[httputil.TypeField] != nil && fmt.Sprint(dataMap[httputil.TypeField]) != fmt.Sprint(originalMWindow[httputil.TypeField]) {
+			return fmt.Errorf(MsgOriginalAndProvidedMWindowConflictType, originalMWindow[httputil.TypeField], dataMap[httputil.TypeField])
+		}
+
+		mWindowType, err := serviceutil.ToInt(originalMWindow[httputil.TypeField])
+		if err != nil {
+			return fmt.Errorf(MsgFieldIsInvalid, httputil.TypeField, fmt.Sprint(originalMWindow[httputil.TypeField]))
+		}
+		if err := validateSchedule(dataMap, uint8(mWindowType)); err != nil {
+			return err
+		}
+
+		editData := make(map[string]interface{})
+		for _, field := range []string{httputil.IdField, httputil.FriendlyNameField, httputil.ValueField, httputil.StartTimeField, httputil.DurationField} {
+			if dataMap[field] != nil {
+				editData[field] = dataMap[field]
+			}
+		}
+		if _, err := service.InitiateRequest(httputil.EditMWindowEndpoint, editData); err != nil {
+			return err
+		}
+	} else {
+		if err := service.isValidPayload(dataMap, model.Create); err != nil {
+			return err
+		}
+		if err := service.CreateRequest(dataMap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (service *MWindowService) CreateRequest(dataMap map[string]interface{}) error {
+	mWindowType, err := resolveProperty(httputil.TypeField, dataMap[httputil.TypeField])
+	if err != nil {
+		return fmt.Errorf(MsgFieldIsInvalid, httputil.TypeField, fmt.Sprint(dataMap[httputil.TypeField]))
+	}
+	if err := validateSchedule(dataMap, mWindowType); err != nil {
+		return err
+	}
+
+	log.Infof("%s %s %s", "creating", dataMap[httputil.FriendlyNameField], "maintenance window")
+	if _, err := service.InitiateRequest(httputil.NewMWindowEndpoint, dataMap); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (service *MWindowService) DeleteRequest(dataMap map[string]interface{}) error {
+	if dataMap[httputil.IdField] == nil && dataMap[httputil.FriendlyNameField] == nil {
+		return fmt.Errorf(MsgFieldMissing, "id or friendly_name")
+	}
+
+	remoteMWindow, err := service.findMWindow(dataMap)
+	if err != nil {
+		return err
+	}
+	if remoteMWindow == nil {
+		return errors.New(MsgMWindowDoesNotExist)
+	}
+
+	_, err = service.InitiateRequest(httputil.DeleteMWindowEndpoint, map[string]interface{}{
+		httputil.IdField: serviceutil.ToString(remoteMWindow[httputil.IdField]),
+	})
+	return err
+}
+
+/*
+*
+Looks up the remote maintenance window by id if present otherwise by friendly_name (unless
+MWINDOW_RESOLVE_BY_FRIENDLY_NAME is false). Returns nil when no maintenance window matches and an error listing the
+candidate ids when more than one maintenance window has the friendly_name.
+*/
+func (service *MWindowService) findMWindow(dataMap map[string]interface{}) (map[string]interface{}, error) {
+	requestBody := map[string]interface{}{}
+	field := httputil.IdField
+	if dataMap[httputil.IdField] != nil {
+		requestBody[httputil.MWindowsField] = dataMap[httputil.IdField]
+	} else {
+		if _, err := service.mWindowResolvableByFriendlyName(); err != nil {
+			return nil, err
+		}
+		field = httputil.FriendlyNameField
+	}
+
+	mWindows, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetMWindowsEndpoint, httputil.MWindowsField, requestBody)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []map[string]interface{}
+	for _, mWindow := range mWindows {
+		if serviceutil.ToString(mWindow[field]) == serviceutil.ToString(dataMap[field]) {
+			candidates = append(candidates, mWindow)
+		}
+	}
+	if len(candidates) > 1 {
+		return nil, fmt.Errorf(MsgAmbiguousMWindow, dataMap[field], serviceutil.JoinIds(candidates))
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return nil, nil
+}
+
+func (service *MWindowService) mWindowResolvableByFriendlyName() (bool, error) {
+	val, found := service.IService.LookUpEnv(MWindowResolveByFriendlyNameEnv)
+	if found {
+		parseBool, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, err
+		}
+
+		if !parseBool {
+			return false, fmt.Errorf("id field missing but one can enable %s env to update by friendly_name", MWindowResolveByFriendlyNameEnv)
+		}
+
+	}
+	return true, nil
+}
+
+func (service *MWindowService) InitiateRequest(endpoint string, data map[string]interface{}) (map[string]interface{}, error) {
+	return serviceutil.InitiateRequest(service.requestContext(), service.IService, endpoint, data)
+}
+
+func New() *MWindowService {
+	mwindowservice := &MWindowService{}
+	mwindowservice.IService = mwindowservice
+	return mwindowservice
+}
+
+type MWindowService struct {
+	service.IService
+	// ctx is the context of the request being handled.
+	ctx context.Context
+}
+
+func (service *MWindowService) HttpInitiatePostRequest(ctx context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	return httputil.New().InitiatePostRequest(ctx, endpoint, dataMap)
+}
+
+// Returns the context of the request being handled, see HandleRequest.
+func (service *MWindowService) requestContext() context.Context {
+	if service.ctx == nil {
+		return context.Background()
+	}
+	return service.ctx
+}
+
+func (service *MWindowService) LookUpEnv(variable string) (string, bool) {
+	return provider.LookUpEnv(variable)
+}
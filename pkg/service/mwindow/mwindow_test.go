@@ -0,0 +1,335 @@
+package mwindow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/service"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"reflect"
+	"testing"
+)
+
+type testMWindowService struct {
+	service.IService
+	mock.Mock
+}
+
+func (t *testMWindowService) HttpInitiatePostRequest(_ context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	args := t.Called(endpoint, dataMap)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func (t *testMWindowService) LookUpEnv(variable string) (string, bool) {
+	args := t.Called(variable)
+	return args.String(0), args.Bool(1)
+}
+
+var mWindowsResponse = map[string]interface{}{
+	"stat": "ok",
+	"pagination": map[string]interface{}{
+		"offset": float64(0),
+		"limit":  float64(50),
+		"total":  float64(2),
+	},
+	"mwindows": []interface{}{
+		map[string]interface{}{
+			"id":            float64(10),
+			"friendly_name": "deploy",
+			"type":          float64(3),
+			"value":         "2-4",
+			"start_time":    "22:00",
+			"duration":      float64(30),
+		},
+		map[string]interface{}{
+			"id":            float64(11),
+			"friendly_name": "migration",
+			"type":          float64(1),
+			"start_time":    float64(1672531200),
+			"duration":      float64(60),
+		},
+	},
+}
+
+func TestMWindowService_HandleRequest(t *testing.T) {
+	var testmwindowservice *testMWindowService
+	mwindowservice := &MWindowService{}
+
+	type args struct {
+		data     []map[string]interface{}
+		argument model.Args
+	}
+	tests := []struct {
+		name        string
+		args        args
+		setupMocks  func()
+		verifyMocks func()
+		want        []map[string]interface{}
+	}{
+		{name: "should return error when weekly window has no value", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "deploy",
+			httputil.TypeField:         "weekly",
+			httputil.StartTimeField:    "22:00",
+			httputil.DurationField:     float64(30),
+		}}, model.Create}, want: []map[string]interface{}{{
+			model.ErrorResultField:       fmt.Errorf("%s maintenance windows require %s", "weekly", httputil.ValueField),
+			model.MWindowNameResultField: "deploy",
+		}}},
+		{name: "should return error when type cannot be mapped", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "deploy",
+			httputil.TypeField:         "yearly",
+			httputil.StartTimeField:    "22:00",
+			httputil.DurationField:     float64(30),
+		}}, model.Create}, want: []map[string]interface{}{{
+			model.ErrorResultField:       fmt.Errorf(MsgMappingNotFound, httputil.TypeField, "yearly"),
+			model.MWindowNameResultField: "deploy",
+		}}},
+		{name: "should create once window converting start_time to a unix timestamp", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.NewMWindowEndpoint, map[string]interface{}{
+				httputil.FriendlyNameField: "migration",
+				httputil.TypeField:         TypeOnce,
+				httputil.StartTimeField:    int64(1672531200),
+				httputil.DurationField:     60,
+			}).Return(map[string]interface{}{}, nil)
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "migration",
+			httputil.TypeField:         "Once",
+			httputil.StartTimeField:    "2023-01-01T00:00:00Z",
+			httputil.DurationField:     float64(60),
+		}}, model.Create}, want: []map[string]interface{}{{model.ErrorResultField: nil, model.MWindowNameResultField: "migration"}}},
+		{name: "should create window given the numeric id of its type", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.NewMWindowEndpoint, map[string]interface{}{
+				httputil.FriendlyNameField: "deploy",
+				httputil.TypeField:         TypeWeekly,
+				httputil.StartTimeField:    "22:00",
+				httputil.DurationField:     30,
+				httputil.ValueField:        "1-3",
+			}).Return(map[string]interface{}{}, nil)
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "deploy",
+			httputil.TypeField:         "3",
+			httputil.StartTimeField:    "22:00",
+			httputil.DurationField:     float64(30),
+			httputil.ValueField:        "1-3",
+		}}, model.Create}, want: []map[string]interface{}{{model.ErrorResultField: nil, model.MWindowNameResultField: "deploy"}}},
+		{name: "should return error when the numeric type is not an id", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "deploy",
+			httputil.TypeField:         float64(2.5),
+			httputil.StartTimeField:    "22:00",
+			httputil.DurationField:     float64(30),
+		}}, model.Create}, want: []map[string]interface{}{{
+			model.ErrorResultField:       fmt.Errorf(MsgMappingNotFound, httputil.TypeField, "2.5"),
+			model.MWindowNameResultField: "deploy",
+		}}},
+		{name: "should return error on delete when window does not exist", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			testmwindowservice.On("LookUpEnv", MWindowResolveByFriendlyNameEnv).Return("", false)
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, mock.IsType(map[string]interface{}{})).Return(mWindowsResponse, nil)
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "missing",
+		}}, model.Delete}, want: []map[string]interface{}{{model.ErrorResultField: errors.New(MsgMWindowDoesNotExist), model.MWindowNameResultField: "missing"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if got := mwindowservice.HandleRequest(context.Background(), tt.args.data, tt.args.argument); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HandleRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateSchedule(t *testing.T) {
+	type args struct {
+		dataMap     map[string]interface{}
+		mWindowType uint8
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantErr   bool
+		mapResult map[string]interface{}
+	}{
+		{name: "should accept a daily start_time in HH:mm", args: args{dataMap: map[string]interface{}{
+			httputil.StartTimeField: "03:30",
+			httputil.DurationField:  float64(15),
+		}, mWindowType: TypeDaily}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.StartTimeField: "03:30",
+			httputil.DurationField:  15,
+		}},
+		{name: "should reject a daily start_time that is not HH:mm", args: args{dataMap: map[string]interface{}{
+			httputil.StartTimeField: "25:30",
+		}, mWindowType: TypeDaily}, wantErr: true},
+		{name: "should reject a once start_time that is not a date", args: args{dataMap: map[string]interface{}{
+			httputil.StartTimeField: "tomorrow",
+		}, mWindowType: TypeOnce}, wantErr: true},
+		{name: "should reject a non positive duration", args: args{dataMap: map[string]interface{}{
+			httputil.DurationField: float64(0),
+		}, mWindowType: TypeDaily}, wantErr: true},
+		{name: "should join weekly days supplied as an array", args: args{dataMap: map[string]interface{}{
+			httputil.ValueField: []interface{}{float64(1), float64(5)},
+		}, mWindowType: TypeWeekly}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.ValueField: "1-5",
+		}},
+		{name: "should reject weekly days out of range", args: args{dataMap: map[string]interface{}{
+			httputil.ValueField: "1-8",
+		}, mWindowType: TypeWeekly}, wantErr: true},
+		{name: "should accept the last day of the month", args: args{dataMap: map[string]interface{}{
+			httputil.ValueField: "15--1",
+		}, mWindowType: TypeMonthly}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.ValueField: "15--1",
+		}},
+		{name: "should reject monthly days out of range", args: args{dataMap: map[string]interface{}{
+			httputil.ValueField: "32",
+		}, mWindowType: TypeMonthly}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSchedule(tt.args.dataMap, tt.args.mWindowType); (err != nil) != tt.wantErr {
+				t.Errorf("validateSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.mapResult != nil && !reflect.DeepEqual(tt.mapResult, tt.args.dataMap) {
+				t.Errorf("validateSchedule() got = %v, want %v", tt.args.dataMap, tt.mapResult)
+			}
+		})
+	}
+}
+
+func TestMWindowService_UpdateRequest(t *testing.T) {
+	var testmwindowservice *testMWindowService
+	mwindowservice := &MWindowService{}
+
+	type args struct {
+		dataMap map[string]interface{}
+	}
+	tests := []struct {
+		name        string
+		args        args
+		setupMocks  func()
+		verifyMocks func()
+		wantErr     bool
+	}{
+		{name: "should edit window resolved by friendly_name", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			testmwindowservice.On("LookUpEnv", MWindowResolveByFriendlyNameEnv).Return("", false)
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, mock.IsType(map[string]interface{}{})).Return(mWindowsResponse, nil)
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.EditMWindowEndpoint, map[string]interface{}{
+				httputil.IdField:           "10",
+				httputil.FriendlyNameField: "deploy",
+				httputil.ValueField:        "1-3-5",
+				httputil.StartTimeField:    "23:00",
+			}).Return(map[string]interface{}{}, nil)
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "deploy",
+			httputil.ValueField:        "1-3-5",
+			httputil.StartTimeField:    "23:00",
+		}}, wantErr: false},
+		{name: "should validate the schedule against the remote type", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			testmwindowservice.On("LookUpEnv", MWindowResolveByFriendlyNameEnv).Return("", false)
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, mock.IsType(map[string]interface{}{})).Return(mWindowsResponse, nil)
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "migration",
+			httputil.StartTimeField:    "23:00",
+		}}, wantErr: true},
+		{name: "should return error when type differs from remote window", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			testmwindowservice.On("LookUpEnv", MWindowResolveByFriendlyNameEnv).Return("", false)
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, mock.IsType(map[string]interface{}{})).Return(mWindowsResponse, nil)
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "deploy",
+			httputil.TypeField:         TypeDaily,
+		}}, wantErr: true},
+		{name: "should create window when it does not exist", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			testmwindowservice.On("LookUpEnv", MWindowResolveByFriendlyNameEnv).Return("", false)
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, mock.IsType(map[string]interface{}{})).Return(mWindowsResponse, nil)
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.NewMWindowEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{}, nil)
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "backup",
+			httputil.TypeField:         TypeDaily,
+			httputil.StartTimeField:    "02:00",
+			httputil.DurationField:     float64(10),
+		}}, wantErr: false},
+		{name: "should fail to resolve by friendly_name when config set to false", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			testmwindowservice.On("LookUpEnv", MWindowResolveByFriendlyNameEnv).Return("false", true)
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "deploy",
+		}}, wantErr: true},
+		{name: "should return error when more than one window has the friendly_name", setupMocks: func() {
+			testmwindowservice = &testMWindowService{}
+			testmwindowservice.On("LookUpEnv", MWindowResolveByFriendlyNameEnv).Return("", false)
+			testmwindowservice.On("HttpInitiatePostRequest", httputil.GetMWindowsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+				"stat": "ok",
+				"mwindows": []interface{}{
+					map[string]interface{}{"id": float64(10), "friendly_name": "deploy", "type": float64(3)},
+					map[string]interface{}{"id": float64(12), "friendly_name": "deploy", "type": float64(3)},
+				},
+			}, nil)
+			mwindowservice.IService = testmwindowservice
+		}, verifyMocks: func() {
+			testmwindowservice.AssertExpectations(t)
+			testmwindowservice.AssertNotCalled(t, "HttpInitiatePostRequest", httputil.EditMWindowEndpoint, mock.Anything)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "deploy",
+			httputil.StartTimeField:    "23:00",
+		}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if err := mwindowservice.UpdateRequest(tt.args.dataMap); (err != nil) != tt.wantErr {
+				t.Errorf("UpdateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
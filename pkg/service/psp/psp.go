@@ -0,0 +1,323 @@
+package psp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/provider"
+	"github.com/onaio/uptimerobot-tooling/pkg/service"
+	"github.com/onaio/uptimerobot-tooling/pkg/service/monitor"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/serviceutil"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+)
+
+const (
+	PSPMonitorsDelimiterEnv     = "PSP_MONITORS_DELIMITER"
+	PSPResolveByFriendlyNameEnv = "PSP_RESOLVE_BY_FRIENDLY_NAME"
+)
+
+const (
+	// AllMonitors is the monitors value that adds every monitor in the account to a status page.
+	AllMonitors = "0"
+	// DefaultType is the only status page type supported by the API.
+	DefaultType = 1
+	// MonitorsDelimiter separates monitor ids in the monitors field.
+	MonitorsDelimiter = "-"
+)
+
+const (
+	MsgPSPDoesNotExist    = "public status page does not exist"
+	MsgFieldMissing       = "%s needs to be specified"
+	MsgMappingNotFound    = "mapping for %s -> %s not found"
+	MsgActionNotSupported = "%s action is not supported"
+	MsgMonitorsNotIds     = "monitors %s can only be separated by %s when they are ids, give friendly names as a list or set %s"
+	MsgAmbiguousPSP       = "more than one public status page is named %s (ids %s), specify the id"
+)
+
+func (service *PSPService) HandleRequest(ctx context.Context, dataMapInterface []map[string]interface{}, action model.Args) []map[string]interface{} {
+	service.ctx = ctx
+	resultArrayMap := make([]map[string]interface{}, len(dataMapInterface))
+	for idx, dataMap := range dataMapInterface {
+		if ctx.Err() != nil {
+			// cancelled, the remaining items are left unhandled
+			break
+		}
+		resultArrayMap[idx] = make(map[string]interface{})
+		if action == model.Create || action == model.Update {
+			if err := service.isValidPayload(dataMap, action); err != nil {
+				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+				return resultArrayMap
+			}
+
+			if err := service.resolvePSPProperties(dataMap); err != nil {
+				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+				return resultArrayMap
+			}
+
+			if action == model.Update {
+				if err := service.UpdateRequest(dataMap); err != nil {
+					resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+					return resultArrayMap
+				}
+			} else {
+				if err := service.CreateRequest(dataMap); err != nil {
+					resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+					return resultArrayMap
+				}
+			}
+			resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+		} else if action == model.Delete {
+			log.Infof("deleting %v public status page", dataMap[httputil.FriendlyNameField])
+
+			if err := service.DeleteRequest(dataMap); err != nil {
+				resultArrayMap = createResultObject(model.Result{ErrorResultField: err, NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+				return resultArrayMap
+			}
+			resultArrayMap = createResultObject(model.Result{NameResultField: dataMap[httputil.FriendlyNameField]}, idx, resultArrayMap)
+
+		} else {
+			log.Fatalf(MsgActionNotSupported, action)
+		}
+	}
+
+	return resultArrayMap
+}
+
+/*
+*
+Populates resultMap on an index with error and public status page name.
+*/
+func createResultObject(result model.Result, index int, resultArrayMap []map[string]interface{}) []map[string]interface{} {
+	resultArrayMap[index][model.ErrorResultField] = result.ErrorResultField
+	if result.NameResultField != nil {
+		resultArrayMap[index][model.PSPNameResultField] = fmt.Sprint(result.NameResultField)
+	} else {
+		resultArrayMap[index][model.PSPNameResultField] = ""
+	}
+	return resultArrayMap
+}
+
+/*
+*
+Checks if the payload supplied is valid i.e.
+On update: friendly_name or id needs to be preset.
+On creation: friendly_name and monitors need to be specified.
+*/
+func (service *PSPService) isValidPayload(dataMap map[string]interface{}, args model.Args) error {
+	if args == model.Update {
+		if dataMap[httputil.FriendlyNameField] == nil && dataMap[httputil.IdField] == nil {
+			return fmt.Errorf("%s or %s is needed for an update/create", httputil.FriendlyNameField, httputil.IdField)
+		}
+		return nil
+	}
+
+	for _, field := range []string{httputil.FriendlyNameField, httputil.MonitorsField} {
+		if dataMap[field] == nil {
+			return fmt.Errorf(MsgFieldMissing, field)
+		}
+	}
+	return nil
+}
+
+/*
+*
+Resolves Public Status Page Properties:
+1. Resolves the monitors field, which can be an array of monitor friendly names or ids or a delimited string of them, to
+"-" delimited monitor ids. The string is delimited by PSP_MONITORS_DELIMITER, or by "-" when it only holds ids. 0 or "all" adds all monitors to the status page.
+2. Resolves properties mapped on model.PSPEnums e.g. z-a -> 2, names are matched case-insensitively and the ids they map
+to are accepted as is.
+*/
+func (service *PSPService) resolvePSPProperties(dataMap map[string]interface{}) error {
+	if monitors, exists := dataMap[httputil.MonitorsField]; exists {
+		var monitorNames []string
+		if monitorArr, ok := monitors.([]interface{}); ok {
+			for _, monitorName := range monitorArr {
+				monitorNames = append(monitorNames, serviceutil.ToString(monitorName))
+			}
+		} else {
+			delimiter, found := service.IService.LookUpEnv(PSPMonitorsDelimiterEnv)
+			if !found {
+				delimiter = MonitorsDelimiter
+			}
+			monitorNames = strings.Split(serviceutil.ToString(monitors), delimiter)
+			if !found && len(monitorNames) > 1 {
+				// friendly names commonly hold the default delimiter e.g. api-staging, so it only separates ids
+				for _, monitorName := range monitorNames {
+					if _, err := strconv.Atoi(strings.TrimSpace(monitorName)); err != nil {
+						return fmt.Errorf(MsgMonitorsNotIds, serviceutil.ToString(monitors), MonitorsDelimiter, PSPMonitorsDelimiterEnv)
+					}
+				}
+			}
+		}
+
+		if len(monitorNames) == 1 && (monitorNames[0] == AllMonitors || strings.EqualFold(monitorNames[0], "all")) {
+			dataMap[httputil.MonitorsField] = AllMonitors
+		} else {
+			for index, monitorName := range monitorNames {
+				monitorNames[index] = strings.TrimSpace(monitorName)
+			}
+			monitorService := (&monitor.MonitorService{IService: service.IService}).WithContext(service.requestContext())
+			monitorIds, err := monitorService.ResolveMonitorIdsByFriendlyName(monitorNames)
+			if err != nil {
+				return err
+			}
+			dataMap[httputil.MonitorsField] = strings.Join(monitorIds, MonitorsDelimiter)
+		}
+	}
+
+	for property, value := range dataMap {
+		if enum, exists := model.PSPEnums[property]; exists {
+			id, resolved := enum.Resolve(value)
+			if !resolved {
+				return fmt.Errorf(MsgMappingNotFound, property, fmt.Sprint(value))
+			}
+			dataMap[property] = uint8(id)
+		}
+	}
+	return nil
+}
+
+func (service *PSPService) UpdateRequest(dataMap map[string]interface{}) error {
+	originalPSP, err := service.findPSP(dataMap)
+	if err != nil {
+		return err
+	}
+
+	if originalPSP != nil {
+		log.Infof("%s %s %s", "updating", dataMap[httputil.FriendlyNameField], "public status page")
+		dataMap[httputil.IdField] = serviceutil.ToString(originalPSP[httputil.IdField])
+		delete(dataMap, httputil.TypeField)
+
+		if _, err := service.InitiateRequest(httputil.EditPSPEndpoint, dataMap); err != nil {
+			return err
+		}
+	} else {
+		if err := service.isValidPayload(dataMap, model.Create); err != nil {
+			return err
+		}
+		if err := service.CreateRequest(dataMap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (service *PSPService) CreateRequest(dataMap map[string]interface{}) error {
+	if dataMap[httputil.TypeField] == nil {
+		dataMap[httputil.TypeField] = DefaultType
+	}
+
+	log.Infof("%s %s %s", "creating", dataMap[httputil.FriendlyNameField], "public status page")
+	if _, err := service.InitiateRequest(httputil.NewPSPEndpoint, dataMap); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (service *PSPService) DeleteRequest(dataMap map[string]interface{}) error {
+	if dataMap[httputil.IdField] == nil && dataMap[httputil.FriendlyNameField] == nil {
+		return fmt.Errorf(MsgFieldMissing, "id or friendly_name")
+	}
+
+	remotePSP, err := service.findPSP(dataMap)
+	if err != nil {
+		return err
+	}
+	if remotePSP == nil {
+		return errors.New(MsgPSPDoesNotExist)
+	}
+
+	_, err = service.InitiateRequest(httputil.DeletePSPEndpoint, map[string]interface{}{
+		httputil.IdField: serviceutil.ToString(remotePSP[httputil.IdField]),
+	})
+	return err
+}
+
+/*
+*
+Looks up the remote public status page by id if present otherwise by friendly_name (unless PSP_RESOLVE_BY_FRIENDLY_NAME
+is false). Returns nil when no public status page matches and an error listing the candidate ids when more than one
+public status page has the friendly_name.
+*/
+func (service *PSPService) findPSP(dataMap map[string]interface{}) (map[string]interface{}, error) {
+	requestBody := map[string]interface{}{}
+	field := httputil.IdField
+	if dataMap[httputil.IdField] != nil {
+		requestBody[httputil.PSPsField] = dataMap[httputil.IdField]
+	} else {
+		if _, err := service.pspResolvableByFriendlyName(); err != nil {
+			return nil, err
+		}
+		field = httputil.FriendlyNameField
+	}
+
+	psps, err := serviceutil.FetchAll(service.requestContext(), service.IService, httputil.GetPSPsEndpoint, httputil.PSPsField, requestBody)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []map[string]interface{}
+	for _, psp := range psps {
+		if serviceutil.ToString(psp[field]) == serviceutil.ToString(dataMap[field]) {
+			candidates = append(candidates, psp)
+		}
+	}
+	if len(candidates) > 1 {
+		return nil, fmt.Errorf(MsgAmbiguousPSP, dataMap[field], serviceutil.JoinIds(candidates))
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return nil, nil
+}
+
+func (service *PSPService) pspResolvableByFriendlyName() (bool, error) {
+	val, found := service.IService.LookUpEnv(PSPResolveByFriendlyNameEnv)
+	if found {
+		parseBool, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, err
+		}
+
+		if !parseBool {
+			return false, fmt.Errorf("id field missing but one can enable %s env to update by friendly_name", PSPResolveByFriendlyNameEnv)
+		}
+
+	}
+	return true, nil
+}
+
+func (service *PSPService) InitiateRequest(endpoint string, data map[string]interface{}) (map[string]interface{}, error) {
+	return serviceutil.InitiateRequest(service.requestContext(), service.IService, endpoint, data)
+}
+
+func New() *PSPService {
+	pspservice := &PSPService{}
+	pspservice.IService = pspservice
+	return pspservice
+}
+
+type PSPService struct {
+	service.IService
+	// ctx is the context of the request being handled.
+	ctx context.Context
+}
+
+func (service *PSPService) HttpInitiatePostRequest(ctx context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	return httputil.New().InitiatePostRequest(ctx, endpoint, dataMap)
+}
+
+// Returns the context of the request being handled, see HandleRequest.
+func (service *PSPService) requestContext() context.Context {
+	if service.ctx == nil {
+		return context.Background()
+	}
+	return service.ctx
+}
+
+func (service *PSPService) LookUpEnv(variable string) (string, bool) {
+	return provider.LookUpEnv(variable)
+}
@@ -0,0 +1,285 @@
+package psp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"github.com/onaio/uptimerobot-tooling/pkg/service"
+	"github.com/onaio/uptimerobot-tooling/pkg/service/monitor"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"reflect"
+	"testing"
+)
+
+type testPSPService struct {
+	service.IService
+	mock.Mock
+}
+
+func (t *testPSPService) HttpInitiatePostRequest(_ context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	args := t.Called(endpoint, dataMap)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func (t *testPSPService) LookUpEnv(variable string) (string, bool) {
+	args := t.Called(variable)
+	return args.String(0), args.Bool(1)
+}
+
+var pspsResponse = map[string]interface{}{
+	"stat": "ok",
+	"pagination": map[string]interface{}{
+		"offset": float64(0),
+		"limit":  float64(50),
+		"total":  float64(1),
+	},
+	"psps": []interface{}{
+		map[string]interface{}{
+			"id":            float64(2345678),
+			"friendly_name": "status",
+			"monitors":      float64(0),
+			"sort":          float64(1),
+			"status":        float64(1),
+		},
+	},
+}
+
+func monitorsResponse(monitors ...map[string]interface{}) map[string]interface{} {
+	monitorArr := make([]interface{}, len(monitors))
+	for index, _monitor := range monitors {
+		monitorArr[index] = _monitor
+	}
+	return map[string]interface{}{"stat": "ok", httputil.MonitorsField: monitorArr}
+}
+
+func TestPSPService_resolvePSPProperties(t *testing.T) {
+	var testpspservice *testPSPService
+	pspservice := &PSPService{}
+
+	type args struct {
+		dataMap map[string]interface{}
+	}
+	tests := []struct {
+		name        string
+		args        args
+		setupMocks  func()
+		verifyMocks func()
+		wantErr     bool
+		mapResult   map[string]interface{}
+	}{
+		{name: "should resolve monitor friendly names supplied as an array", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			testpspservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(monitorsResponse(
+				map[string]interface{}{httputil.IdField: float64(777712827), httputil.FriendlyNameField: "api-staging"},
+				map[string]interface{}{httputil.IdField: float64(777712828), httputil.FriendlyNameField: "api"},
+				map[string]interface{}{httputil.IdField: float64(5), httputil.FriendlyNameField: "web"},
+			), nil).Once()
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.FriendlyNameField: "status",
+			httputil.MonitorsField:     []interface{}{"api", "web"},
+			httputil.SortField:         "Z-A",
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.FriendlyNameField: "status",
+			httputil.MonitorsField:     "777712828-5",
+			httputil.SortField:         uint8(2),
+		}},
+		{name: "should resolve monitors using the configured delimiter", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			testpspservice.On("LookUpEnv", PSPMonitorsDelimiterEnv).Return("|", true)
+			testpspservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(monitorsResponse(
+				map[string]interface{}{httputil.IdField: float64(4), httputil.FriendlyNameField: "api-staging"},
+			), nil).Once()
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.MonitorsField: "api-staging|12",
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.MonitorsField: "4-12",
+		}},
+		{name: "should not split friendly names on the default delimiter", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			testpspservice.On("LookUpEnv", PSPMonitorsDelimiterEnv).Return("", false)
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.MonitorsField: "api-staging",
+		}}, wantErr: true},
+		{name: "should resolve all monitors without a lookup", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.MonitorsField: []interface{}{"all"},
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.MonitorsField: AllMonitors,
+		}},
+		{name: "should resolve sort and status by name or id", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.SortField:   float64(3),
+			httputil.StatusField: "Paused",
+		}}, wantErr: false, mapResult: map[string]interface{}{
+			httputil.SortField:   uint8(3),
+			httputil.StatusField: uint8(0),
+		}},
+		{name: "should fail on an unknown sort", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.SortField: "newest",
+		}}, wantErr: true},
+		{name: "should fail when a monitor cannot be resolved", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			testpspservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(monitorsResponse(), nil)
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{dataMap: map[string]interface{}{
+			httputil.MonitorsField: []interface{}{"missing"},
+		}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if err := pspservice.resolvePSPProperties(tt.args.dataMap); (err != nil) != tt.wantErr {
+				t.Errorf("resolvePSPProperties() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.mapResult != nil && !reflect.DeepEqual(tt.mapResult, tt.args.dataMap) {
+				t.Errorf("resolvePSPProperties() got = %v, want %v", tt.args.dataMap, tt.mapResult)
+			}
+		})
+	}
+}
+
+func TestPSPService_HandleRequest(t *testing.T) {
+	var testpspservice *testPSPService
+	pspservice := &PSPService{}
+
+	type args struct {
+		data     []map[string]interface{}
+		argument model.Args
+	}
+	tests := []struct {
+		name        string
+		args        args
+		setupMocks  func()
+		verifyMocks func()
+		want        []map[string]interface{}
+	}{
+		{name: "should return error when monitors are missing on create", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "status",
+		}}, model.Create}, want: []map[string]interface{}{{
+			model.ErrorResultField:   fmt.Errorf(MsgFieldMissing, httputil.MonitorsField),
+			model.PSPNameResultField: "status",
+		}}},
+		{name: "should return error when monitor cannot be resolved", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			testpspservice.On("HttpInitiatePostRequest", httputil.GetMonitorsEndpoint, mock.IsType(map[string]interface{}{})).Return(monitorsResponse(), nil)
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "status",
+			httputil.MonitorsField:     []interface{}{"missing"},
+		}}, model.Create}, want: []map[string]interface{}{{
+			model.ErrorResultField:   fmt.Errorf(monitor.MsgMonitorNotResolved, "missing"),
+			model.PSPNameResultField: "status",
+		}}},
+		{name: "should edit status page resolved by friendly_name", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			testpspservice.On("LookUpEnv", PSPMonitorsDelimiterEnv).Return("", false)
+			testpspservice.On("LookUpEnv", PSPResolveByFriendlyNameEnv).Return("", false)
+			testpspservice.On("HttpInitiatePostRequest", httputil.GetPSPsEndpoint, mock.IsType(map[string]interface{}{})).Return(pspsResponse, nil)
+			testpspservice.On("HttpInitiatePostRequest", httputil.EditPSPEndpoint, map[string]interface{}{
+				httputil.IdField:           "2345678",
+				httputil.FriendlyNameField: "status",
+				httputil.MonitorsField:     AllMonitors,
+			}).Return(map[string]interface{}{}, nil)
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "status",
+			httputil.MonitorsField:     float64(0),
+		}}, model.Update}, want: []map[string]interface{}{{model.ErrorResultField: nil, model.PSPNameResultField: "status"}}},
+		{name: "should create status page with default type when it does not exist", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			testpspservice.On("LookUpEnv", PSPMonitorsDelimiterEnv).Return("", false)
+			testpspservice.On("LookUpEnv", PSPResolveByFriendlyNameEnv).Return("", false)
+			testpspservice.On("HttpInitiatePostRequest", httputil.GetPSPsEndpoint, mock.IsType(map[string]interface{}{})).Return(pspsResponse, nil)
+			testpspservice.On("HttpInitiatePostRequest", httputil.NewPSPEndpoint, map[string]interface{}{
+				httputil.TypeField:         DefaultType,
+				httputil.FriendlyNameField: "internal",
+				httputil.MonitorsField:     AllMonitors,
+			}).Return(map[string]interface{}{}, nil)
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "internal",
+			httputil.MonitorsField:     "0",
+		}}, model.Update}, want: []map[string]interface{}{{model.ErrorResultField: nil, model.PSPNameResultField: "internal"}}},
+		{name: "should return error on delete when status page does not exist", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			testpspservice.On("HttpInitiatePostRequest", httputil.GetPSPsEndpoint, map[string]interface{}{
+				httputil.PSPsField:   "1",
+				httputil.OffsetField: 0,
+			}).Return(pspsResponse, nil)
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.IdField: "1",
+		}}, model.Delete}, want: []map[string]interface{}{{model.ErrorResultField: errors.New(MsgPSPDoesNotExist), model.PSPNameResultField: ""}}},
+		{name: "should return error on delete when more than one status page has the friendly_name", setupMocks: func() {
+			testpspservice = &testPSPService{}
+			testpspservice.On("LookUpEnv", PSPResolveByFriendlyNameEnv).Return("", false)
+			testpspservice.On("HttpInitiatePostRequest", httputil.GetPSPsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+				"stat": "ok",
+				"psps": []interface{}{
+					map[string]interface{}{"id": float64(2345678), "friendly_name": "status"},
+					map[string]interface{}{"id": float64(2345679), "friendly_name": "status"},
+				},
+			}, nil)
+			pspservice.IService = testpspservice
+		}, verifyMocks: func() {
+			testpspservice.AssertExpectations(t)
+		}, args: args{[]map[string]interface{}{{
+			httputil.FriendlyNameField: "status",
+		}}, model.Delete}, want: []map[string]interface{}{{
+			model.ErrorResultField:   fmt.Errorf(MsgAmbiguousPSP, "status", "2345678, 2345679"),
+			model.PSPNameResultField: "status",
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			if got := pspservice.HandleRequest(context.Background(), tt.args.data, tt.args.argument); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HandleRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
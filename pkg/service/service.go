@@ -1,14 +1,15 @@
 package service
 
 import (
+	"context"
 	"github.com/onaio/uptimerobot-tooling/pkg/model"
 	"github.com/onaio/uptimerobot-tooling/pkg/provider"
 )
 
 type IService interface {
 	provider.IConfigProvider
-	HttpInitiatePostRequest(endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error)
-	HandleRequest(dataMapInterface []map[string]interface{}, action model.Args) []map[string]interface{}
+	HttpInitiatePostRequest(ctx context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error)
+	HandleRequest(ctx context.Context, dataMapInterface []map[string]interface{}, action model.Args) []map[string]interface{}
 }
 
 type NopService struct{}
@@ -19,10 +20,10 @@ func (s *NopService) LookUpEnv(variable string) (string, bool) {
 	return "", false
 }
 
-func (s *NopService) HttpInitiatePostRequest(endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+func (s *NopService) HttpInitiatePostRequest(ctx context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
 	return map[string]interface{}{}, nil
 }
 
-func (s *NopService) HandleRequest([]map[string]interface{}, model.Args) []map[string]interface{} {
+func (s *NopService) HandleRequest(context.Context, []map[string]interface{}, model.Args) []map[string]interface{} {
 	return []map[string]interface{}{}
 }
@@ -0,0 +1,166 @@
+package httputil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	MsgCustomHttpHeadersInvalid  = "custom_http_headers is invalid, expected an object of header names to values e.g. {\"X-Token\": \"a\"}"
+	MsgCustomHttpHeaderInvalid   = "custom_http_headers header %q is invalid"
+	MsgCustomHttpHeaderNotPair   = "custom_http_headers header %d is invalid, expected \"Name: value\""
+	MsgCustomHttpStatusesInvalid = "custom_http_statuses %s is invalid, expected status:type pairs e.g. 404:0_200:1 where type is 0 (down) or 1 (up)"
+)
+
+const (
+	ListDelimiter               = "-"
+	CustomHttpStatusesDelimiter = "_"
+	CustomHttpStatusDelimiter   = ":"
+)
+
+// Names accepted for the type of a custom http status, besides 0 and 1.
+var customHttpStatusTypes = map[string]string{"down": "0", "up": "1"}
+
+// Fields whose values are not sent as is, see EncodeField.
+var fieldEncoders = map[string]func(value interface{}) (string, error){
+	CustomHttpHeadersField:  encodeCustomHttpHeaders,
+	CustomHttpStatusesField: encodeCustomHttpStatuses,
+	AlertContactsField:      encodeList,
+	MWindowsField:           encodeList,
+}
+
+/*
+*
+Formats the value of field the way the API expects it:
+1. custom_http_headers as a JSON object, given either as an object, a JSON string or a list of "Name: value" strings.
+2. custom_http_statuses as status:type pairs separated by _, given either as a string e.g. 404:0_200:1, a list of pairs or an
+object of statuses to types where the type is 0/down or 1/up e.g. {"404": "down"}.
+3. lists of alert_contacts and mwindows joined by -.
+Other fields are formatted with FormValue. An error is returned when the value cannot be encoded.
+*/
+func EncodeField(field string, value interface{}) (string, error) {
+	if encoder, exists := fieldEncoders[field]; exists {
+		return encoder(value)
+	}
+	return FormValue(value), nil
+}
+
+func encodeCustomHttpHeaders(value interface{}) (string, error) {
+	headers := make(map[string]string)
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return "", nil
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(v), &object); err != nil {
+			return "", errors.New(MsgCustomHttpHeadersInvalid)
+		}
+		for name, headerValue := range object {
+			headers[name] = FormValue(headerValue)
+		}
+	case map[string]string:
+		for name, headerValue := range v {
+			headers[name] = headerValue
+		}
+	case map[string]interface{}:
+		for name, headerValue := range v {
+			headers[name] = FormValue(headerValue)
+		}
+	case []interface{}:
+		for idx, item := range v {
+			header := strings.SplitN(FormValue(item), ":", 2)
+			if len(header) != 2 {
+				return "", fmt.Errorf(MsgCustomHttpHeaderNotPair, idx)
+			}
+			headers[strings.TrimSpace(header[0])] = strings.TrimSpace(header[1])
+		}
+	default:
+		return "", errors.New(MsgCustomHttpHeadersInvalid)
+	}
+
+	for name := range headers {
+		if name == "" || strings.ContainsAny(name, " :\t\r\n") {
+			return "", fmt.Errorf(MsgCustomHttpHeaderInvalid, name)
+		}
+	}
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func encodeCustomHttpStatuses(value interface{}) (string, error) {
+	var pairs [][2]string
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return "", nil
+		}
+		for _, pair := range strings.Split(v, CustomHttpStatusesDelimiter) {
+			pairs = append(pairs, splitCustomHttpStatus(pair))
+		}
+	case []interface{}:
+		for _, item := range v {
+			pairs = append(pairs, splitCustomHttpStatus(FormValue(item)))
+		}
+	case map[string]interface{}:
+		statuses := make([]string, 0, len(v))
+		for status := range v {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			pairs = append(pairs, [2]string{status, FormValue(v[status])})
+		}
+	default:
+		return "", fmt.Errorf(MsgCustomHttpStatusesInvalid, FormValue(value))
+	}
+
+	encoded := make([]string, len(pairs))
+	for idx, pair := range pairs {
+		status, statusType := strings.TrimSpace(pair[0]), strings.ToLower(strings.TrimSpace(pair[1]))
+		if code, err := strconv.Atoi(status); err != nil || code < 100 || code > 599 {
+			return "", fmt.Errorf(MsgCustomHttpStatusesInvalid, FormValue(value))
+		}
+		if _statusType, exists := customHttpStatusTypes[statusType]; exists {
+			statusType = _statusType
+		}
+		if statusType != "0" && statusType != "1" {
+			return "", fmt.Errorf(MsgCustomHttpStatusesInvalid, FormValue(value))
+		}
+		encoded[idx] = status + CustomHttpStatusDelimiter + statusType
+	}
+	return strings.Join(encoded, CustomHttpStatusesDelimiter), nil
+}
+
+// Splits a status:type pair, the type is left empty when the delimiter is missing so that the pair is rejected.
+func splitCustomHttpStatus(pair string) [2]string {
+	parts := strings.SplitN(pair, CustomHttpStatusDelimiter, 2)
+	if len(parts) != 2 {
+		return [2]string{parts[0], ""}
+	}
+	return [2]string{parts[0], parts[1]}
+}
+
+// Joins lists with ListDelimiter, other values are formatted with FormValue.
+func encodeList(value interface{}) (string, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return FormValue(value), nil
+	}
+	encoded := make([]string, len(items))
+	for idx, item := range items {
+		encoded[idx] = FormValue(item)
+	}
+	return strings.Join(encoded, ListDelimiter), nil
+}
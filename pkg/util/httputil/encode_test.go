@@ -0,0 +1,64 @@
+package httputil
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeField(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "should encode header objects as JSON", field: CustomHttpHeadersField, value: map[string]interface{}{"X-Token": "a", "X-Retries": float64(3)}, want: `{"X-Retries":"3","X-Token":"a"}`},
+		{name: "should accept headers as a JSON string", field: CustomHttpHeadersField, value: `{"X-Token": "a"}`, want: `{"X-Token":"a"}`},
+		{name: "should accept headers as a list of name: value", field: CustomHttpHeadersField, value: []interface{}{"X-Token: a:b"}, want: `{"X-Token":"a:b"}`},
+		{name: "should reject headers that are not an object", field: CustomHttpHeadersField, value: "X-Token=a", wantErr: true},
+		{name: "should reject invalid header names", field: CustomHttpHeadersField, value: map[string]interface{}{"X Token": "a"}, wantErr: true},
+		{name: "should keep valid statuses", field: CustomHttpStatusesField, value: "404:0_200:1", want: "404:0_200:1"},
+		{name: "should accept statuses as a list", field: CustomHttpStatusesField, value: []interface{}{"404:down", "200:1"}, want: "404:0_200:1"},
+		{name: "should accept statuses as an object sorted by status", field: CustomHttpStatusesField, value: map[string]interface{}{"404": float64(0), "200": "Up"}, want: "200:1_404:0"},
+		{name: "should reject statuses without a type", field: CustomHttpStatusesField, value: "404", wantErr: true},
+		{name: "should reject unknown status types", field: CustomHttpStatusesField, value: "404:2", wantErr: true},
+		{name: "should reject invalid status codes", field: CustomHttpStatusesField, value: "4040:0", wantErr: true},
+		{name: "should join lists of alert contacts", field: AlertContactsField, value: []interface{}{"1_0_5", float64(2)}, want: "1_0_5-2"},
+		{name: "should keep delimited mwindows", field: MWindowsField, value: "10-11", want: "10-11"},
+		{name: "should format other fields as is", field: IntervalField, value: float64(300), want: "300"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeField(tt.field, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EncodeField() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("EncodeField() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeField_HeaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr error
+	}{
+		{name: "should not include the headers when they are not JSON", value: "Authorization=Bearer tok3n", wantErr: errors.New(MsgCustomHttpHeadersInvalid)},
+		{name: "should only include the index of a header that is not a pair", value: []interface{}{"X-Env: staging", "Authorization Bearer tok3n"}, wantErr: fmt.Errorf(MsgCustomHttpHeaderNotPair, 1)},
+		{name: "should only include the name of an invalid header", value: map[string]interface{}{"X Token": "tok3n"}, wantErr: fmt.Errorf(MsgCustomHttpHeaderInvalid, "X Token")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodeField(CustomHttpHeadersField, tt.value); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("EncodeField() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,53 +1,90 @@
 package httputil
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
 	"github.com/onaio/uptimerobot-tooling/pkg/provider"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
 	log "github.com/sirupsen/logrus"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	AlertContactsField   = "alert_contacts"
-	TotalField           = "total"
-	LimitField           = "limit"
-	OffsetField          = "offset"
-	TypeField            = "type"
-	StatField            = "stat"
-	SubTypeField         = "sub_type"
-	PortField            = "port"
-	KeywordTypeField     = "keyword_type"
-	KeywordField         = "keyword"
-	KeywordCaseTypeField = "keyword_case_type"
-	KeywordValueField    = "keyword_value"
-	MonitorsField        = "monitors"
-	IdField              = "id"
-	FriendlyNameField    = "friendly_name"
-	UrlField             = "url"
-	TypesField           = "types"
-	SearchField          = "search"
-	ErrorField           = "error"
-	MessageField         = "message"
-	ApiKeyField          = "api_key"
-	FormatKeyField       = "format"
-	CacheControlField    = "cache-control"
-	ContentTypeField     = "content-type"
-	HttpMethodField      = "http_method"
-	HttpAuthTypeField    = "http_auth_type"
-	PostContentTypeField = "post_content_type"
-	PostValueField       = "post_value"
+	AlertContactsField      = "alert_contacts"
+	TotalField              = "total"
+	LimitField              = "limit"
+	OffsetField             = "offset"
+	TypeField               = "type"
+	StatField               = "stat"
+	SubTypeField            = "sub_type"
+	PortField               = "port"
+	KeywordTypeField        = "keyword_type"
+	KeywordField            = "keyword"
+	KeywordCaseTypeField    = "keyword_case_type"
+	KeywordValueField       = "keyword_value"
+	MonitorsField           = "monitors"
+	MonitorField            = "monitor"
+	IdField                 = "id"
+	FriendlyNameField       = "friendly_name"
+	UrlField                = "url"
+	TypesField              = "types"
+	SearchField             = "search"
+	ErrorField              = "error"
+	MessageField            = "message"
+	ApiKeyField             = "api_key"
+	FormatKeyField          = "format"
+	CacheControlField       = "cache-control"
+	ContentTypeField        = "content-type"
+	HttpMethodField         = "http_method"
+	HttpAuthTypeField       = "http_auth_type"
+	PostContentTypeField    = "post_content_type"
+	PostValueField          = "post_value"
+	PostTypeField           = "post_type"
+	ValueField              = "value"
+	StatusField             = "status"
+	PaginationField         = "pagination"
+	MWindowsField           = "mwindows"
+	StartTimeField          = "start_time"
+	DurationField           = "duration"
+	PSPsField               = "psps"
+	SortField               = "sort"
+	StatusesField           = "statuses"
+	IntervalField           = "interval"
+	TimeoutField            = "timeout"
+	HttpUsernameField       = "http_username"
+	HttpPasswordField       = "http_password"
+	ThresholdField          = "threshold"
+	RecurrenceField         = "recurrence"
+	CustomHttpHeadersField  = "custom_http_headers"
+	CustomHttpStatusesField = "custom_http_statuses"
 )
 
 const (
-	UptimeRobotApiKeyEnv = "UPTIME_ROBOT_API_KEY"
-	UptimeRobotApiUrlEnv = "UPTIME_ROBOT_API_URL"
+	UptimeRobotApiKeyEnv         = "UPTIME_ROBOT_API_KEY"
+	UptimeRobotApiKeyFileEnv     = "UPTIME_ROBOT_API_KEY_FILE"
+	UptimeRobotApiUrlEnv         = "UPTIME_ROBOT_API_URL"
+	UptimeRobotRequestTimeoutEnv = "UPTIME_ROBOT_REQUEST_TIMEOUT"
 )
+
+/*
+*
+Flags that make getMonitors include these fields in the monitors it returns e.g. custom_http_headers=1. They share their
+names with the fields so they are kept out of EncodeField.
+*/
+var MonitorDetailFlags = []string{AlertContactsField, MWindowsField, CustomHttpHeadersField, CustomHttpStatusesField}
+
+// DefaultRequestTimeout is how long a single attempt of a request may take.
+const DefaultRequestTimeout = 30 * time.Second
 const (
 	UptimeRobotApiUrl = "https://api.uptimerobot.com/v2/"
 )
@@ -58,15 +95,27 @@ const (
 )
 
 const (
-	ErrorApiKeyUndefined = "API key is undefined"
+	ErrorApiKeyUndefined      = "API key is undefined"
+	ErrorApiKeyFileUnreadable = "API key could not be read from %s: %v"
 )
 
 const (
-	GetMonitorsEndpoint      = "getMonitors"
-	DeleteMonitorEndpoint    = "deleteMonitor"
-	EditMonitorEndpoint      = "editMonitor"
-	NewMonitorEndpoint       = "newMonitor"
-	GetAlertContactsEndpoint = "getAlertContacts"
+	GetMonitorsEndpoint        = "getMonitors"
+	DeleteMonitorEndpoint      = "deleteMonitor"
+	EditMonitorEndpoint        = "editMonitor"
+	NewMonitorEndpoint         = "newMonitor"
+	GetAlertContactsEndpoint   = "getAlertContacts"
+	NewAlertContactEndpoint    = "newAlertContact"
+	EditAlertContactEndpoint   = "editAlertContact"
+	DeleteAlertContactEndpoint = "deleteAlertContact"
+	GetMWindowsEndpoint        = "getMWindows"
+	NewMWindowEndpoint         = "newMWindow"
+	EditMWindowEndpoint        = "editMWindow"
+	DeleteMWindowEndpoint      = "deleteMWindow"
+	GetPSPsEndpoint            = "getPSPs"
+	NewPSPEndpoint             = "newPSP"
+	EditPSPEndpoint            = "editPSP"
+	DeletePSPEndpoint          = "deletePSP"
 )
 
 func ValidateUrl(host string) bool {
@@ -74,45 +123,119 @@ func ValidateUrl(host string) bool {
 	return err == nil
 }
 
-func (util *HttpUtil) InitiatePostRequest(endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
-	return util.initiateRequest("POST", endpoint, dataMap)
+/*
+*
+Sends dataMap to endpoint. Every attempt is bound by UPTIME_ROBOT_REQUEST_TIMEOUT and the whole request, retries
+included, stops once ctx is done.
+*/
+func (util *HttpUtil) InitiatePostRequest(ctx context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	return util.initiateRequest(ctx, "POST", endpoint, dataMap)
 }
 
-func (util *HttpUtil) initiateRequest(method string, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+func (util *HttpUtil) initiateRequest(ctx context.Context, method string, endpoint string, data map[string]interface{}) (map[string]interface{}, error) {
+	// the api key is added to a copy so that it does not end up in the errors and logs of the caller
+	dataMap := make(map[string]interface{}, len(data)+2)
+	for key, value := range data {
+		dataMap[key] = value
+	}
 	cleanPayload(dataMap)
 
-	apiKey, found := util.IHttpUtil.LookUpEnv(UptimeRobotApiKeyEnv)
-	if found {
-		dataMap[ApiKeyField] = apiKey
-	} else {
-		return nil, errors.New(ErrorApiKeyUndefined)
+	apiKey, err := ApiKey(util.IHttpUtil)
+	if err != nil {
+		return nil, err
 	}
+	redactutil.AddSecret(apiKey)
+	dataMap[ApiKeyField] = apiKey
 
 	uptimeRobotUrl, found := util.IHttpUtil.LookUpEnv(UptimeRobotApiUrlEnv)
 	if !found {
 		uptimeRobotUrl = UptimeRobotApiUrl
 	}
 
+	timeout := DefaultRequestTimeout
+	if val, found := util.IHttpUtil.LookUpEnv(UptimeRobotRequestTimeoutEnv); found {
+		duration, err := time.ParseDuration(val)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid %s: %s needs to be a duration e.g. 30s", UptimeRobotRequestTimeoutEnv, val)
+		}
+		timeout = duration
+	}
+
 	dataMap[FormatKeyField] = "json"
 	form := url.Values{}
 	for key, value := range dataMap {
+		encoded, err := encodeRequestField(endpoint, key, value)
+		if err != nil {
+			return nil, err
+		}
 		form.Add(
 			key,
-			fmt.Sprint(value),
+			encoded,
 		)
 	}
+	encodedForm := form.Encode()
 
-	payload := strings.NewReader(form.Encode())
-	req, err := util.IHttpUtil.newRequest(method, uptimeRobotUrl+endpoint, payload)
-	if err != nil {
-		return nil, err
+	var policy *retryPolicy
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		req, err := util.IHttpUtil.newRequest(attemptCtx, method, uptimeRobotUrl+endpoint, strings.NewReader(encodedForm))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		req.Header.Add(ContentTypeField, FormUrlEncodedContentType)
+		req.Header.Add(CacheControlField, CacheControlValue)
+
+		res, body, err := util.doRequest(req)
+		cancel()
+		if err != nil && ctx.Err() != nil {
+			// cancelled or out of time, a response that made it in time is still used as the request may have had effect
+			return nil, ctx.Err()
+		}
+		if err == nil && res.StatusCode == http.StatusOK {
+			var resultMap map[string]interface{}
+			err = json.Unmarshal(body, &resultMap)
+			if err != nil {
+				return nil, err
+			}
+
+			return resultMap, nil
+		}
+
+		var header http.Header
+		retryable := err != nil
+		if err == nil {
+			header = res.Header
+			retryable = res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+			err = errors.New(string(body))
+		}
+		if !retryable || !IsRetryableEndpoint(endpoint) {
+			return nil, err
+		}
+
+		if policy == nil {
+			var policyErr error
+			if policy, policyErr = util.retryPolicy(); policyErr != nil {
+				return nil, policyErr
+			}
+		}
+		if attempt >= policy.maxAttempts {
+			return nil, err
+		}
+		delay := policy.delay(attempt, header)
+		log.Warnf(MsgRetryingRequest, endpoint, err, delay, attempt+1, policy.maxAttempts)
+		if err := util.IHttpUtil.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
 	}
-	req.Header.Add(ContentTypeField, FormUrlEncodedContentType)
-	req.Header.Add(CacheControlField, CacheControlValue)
+}
+
+// Sends req and returns the response with its body read.
+func (util *HttpUtil) doRequest(req *http.Request) (*http.Response, []byte, error) {
 	res, err := util.IHttpUtil.makeRequest(req)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer func(Body io.ReadCloser) {
@@ -124,19 +247,63 @@ func (util *HttpUtil) initiateRequest(method string, endpoint string, dataMap ma
 	body, err := io.ReadAll(res.Body)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	if res.StatusCode != http.StatusOK {
-		return nil, errors.New(string(body))
+	return res, body, nil
+}
+
+/*
+*
+Formats value as a form value of a request: enums (see model.Enum) as the number the API uses, numbers without an exponent
+and maps, slices and structs e.g. custom_http_headers as JSON.
+*/
+func FormValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case model.Enum:
+		return strconv.Itoa(v.Int())
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case fmt.Stringer:
+		return v.String()
 	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if encoded, err := json.Marshal(value); err == nil {
+			return string(encoded)
+		}
+	}
+	return fmt.Sprint(value)
+}
 
-	var resultMap map[string]interface{}
-	err = json.Unmarshal(body, &resultMap)
+/*
+*
+Posts payload, e.g. a model.MonitorConfig, to endpoint and decodes the response into response, which needs to be a
+pointer. Enums are sent as the numbers the API uses (see FormValue).
+*/
+func (util *HttpUtil) PostForm(ctx context.Context, endpoint string, payload interface{}, response interface{}) error {
+	dataMap, err := formMap(payload)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	resultMap, err := util.InitiatePostRequest(ctx, endpoint, dataMap)
+	if err != nil {
+		return err
 	}
+	return model.FromMap(resultMap, response)
+}
 
-	return resultMap, nil
+// Converts payload into a map keeping the enum values typed so that they are sent as numbers rather than names.
+func formMap(payload interface{}) (map[string]interface{}, error) {
+	if dataMap, ok := payload.(map[string]interface{}); ok {
+		return dataMap, nil
+	}
+	return model.ToMap(payload)
 }
 
 func New() *HttpUtil {
@@ -145,12 +312,41 @@ func New() *HttpUtil {
 	return httputil
 }
 
+// Returns value formatted for a request to endpoint, the flags of getMonitors are sent as is (see MonitorDetailFlags).
+func encodeRequestField(endpoint string, field string, value interface{}) (string, error) {
+	if endpoint == GetMonitorsEndpoint {
+		for _, flag := range MonitorDetailFlags {
+			if field == flag {
+				return FormValue(value), nil
+			}
+		}
+	}
+	return EncodeField(field, value)
+}
+
+/*
+*
+Returns UPTIME_ROBOT_API_KEY or else the content of the UPTIME_ROBOT_API_KEY_FILE file, trimmed of surrounding
+whitespace, as found by configProvider.
+*/
+func ApiKey(configProvider provider.IConfigProvider) (string, error) {
+	if apiKey, found := configProvider.LookUpEnv(UptimeRobotApiKeyEnv); found {
+		return apiKey, nil
+	}
+	keyFile, found := configProvider.LookUpEnv(UptimeRobotApiKeyFileEnv)
+	if !found {
+		return "", errors.New(ErrorApiKeyUndefined)
+	}
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf(ErrorApiKeyFileUnreadable, keyFile, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
 func cleanPayload(dataMap map[string]interface{}) {
 	delete(dataMap, FormatKeyField)
 	delete(dataMap, ApiKeyField)
-	delete(dataMap, HttpMethodField)
-	delete(dataMap, PostValueField)
-	delete(dataMap, PostContentTypeField)
 }
 
 type HttpUtil struct {
@@ -158,22 +354,35 @@ type HttpUtil struct {
 }
 
 func (util *HttpUtil) LookUpEnv(variable string) (string, bool) {
-	return os.LookupEnv(variable)
+	return provider.LookUpEnv(variable)
 }
 
-func (util *HttpUtil) newRequest(method, url string, body io.Reader) (*http.Request, error) {
-	return http.NewRequest(method, url, body)
+func (util *HttpUtil) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, url, body)
 }
 
 func (util *HttpUtil) makeRequest(req *http.Request) (*http.Response, error) {
 	return http.DefaultClient.Do(req)
 }
 
+// Waits for duration unless ctx is done first, in which case the context error is returned.
+func (util *HttpUtil) sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 var _ IHttpUtil = &HttpUtil{}
 var _ provider.IConfigProvider = &HttpUtil{}
 
 type IHttpUtil interface {
 	provider.IConfigProvider
-	newRequest(method, url string, body io.Reader) (*http.Request, error)
+	newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error)
 	makeRequest(req *http.Request) (*http.Response, error)
+	sleep(ctx context.Context, duration time.Duration) error
 }
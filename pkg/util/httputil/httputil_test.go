@@ -1,14 +1,19 @@
 package httputil
 
 import (
+	"context"
 	"errors"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
 	"github.com/stretchr/testify/mock"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 type testHttpUtil struct {
@@ -20,7 +25,7 @@ func (t *testHttpUtil) LookUpEnv(variable string) (string, bool) {
 	return args.String(0), args.Bool(1)
 }
 
-func (t *testHttpUtil) newRequest(method, url string, body io.Reader) (*http.Request, error) {
+func (t *testHttpUtil) newRequest(_ context.Context, method, url string, body io.Reader) (*http.Request, error) {
 	args := t.Called(method, url, body)
 	return args.Get(0).(*http.Request), args.Error(1)
 }
@@ -30,6 +35,11 @@ func (t *testHttpUtil) makeRequest(req *http.Request) (*http.Response, error) {
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
+func (t *testHttpUtil) sleep(_ context.Context, duration time.Duration) error {
+	t.Called(duration)
+	return nil
+}
+
 var _ IHttpUtil = &testHttpUtil{}
 
 func TestInitiatePostRequest(t *testing.T) {
@@ -50,6 +60,7 @@ func TestInitiatePostRequest(t *testing.T) {
 		{name: "should fail if API key is undefined", setupMocks: func() {
 			testutil = &testHttpUtil{}
 			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("", false)
+			testutil.On("LookUpEnv", UptimeRobotApiKeyFileEnv).Return("", false)
 			httputil.IHttpUtil = testutil
 
 		}, verifyMocks: func() {
@@ -60,6 +71,8 @@ func TestInitiatePostRequest(t *testing.T) {
 				testutil = &testHttpUtil{}
 				testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
 				testutil.On("LookUpEnv", UptimeRobotApiUrlEnv).Return("https://localhost", true)
+				testutil.On("LookUpEnv", UptimeRobotRequestTimeoutEnv).Return("", false)
+				testutil.On("LookUpEnv", UptimeRobotRequestTimeoutEnv).Return("", false)
 				res := httptest.NewRequest("POST", "https://localhost", strings.NewReader("api_key=api-key&format=json"))
 				testutil.On("newRequest", "POST", "https://localhost", strings.NewReader("api_key=api-key&format=json")).Return(res, errors.New("test error"))
 				httputil.IHttpUtil = testutil
@@ -72,6 +85,7 @@ func TestInitiatePostRequest(t *testing.T) {
 			testutil = &testHttpUtil{}
 			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
 			testutil.On("LookUpEnv", UptimeRobotApiUrlEnv).Return("https://localhost", true)
+			testutil.On("LookUpEnv", UptimeRobotRequestTimeoutEnv).Return("", false)
 			res := httptest.NewRequest("POST", "https://localhost", strings.NewReader("api_key=api-key&format=json"))
 			testutil.On("newRequest", "POST", "https://localhost", strings.NewReader("api_key=api-key&format=json")).Return(res, nil)
 			testutil.On("makeRequest", res).Return(&http.Response{Body: io.NopCloser(strings.NewReader("{}")), StatusCode: http.StatusBadRequest}, nil)
@@ -84,6 +98,7 @@ func TestInitiatePostRequest(t *testing.T) {
 			testutil = &testHttpUtil{}
 			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
 			testutil.On("LookUpEnv", UptimeRobotApiUrlEnv).Return("https://localhost", true)
+			testutil.On("LookUpEnv", UptimeRobotRequestTimeoutEnv).Return("", false)
 			res := httptest.NewRequest("POST", "https://localhost", strings.NewReader("api_key=api-key&format=json"))
 			testutil.On("newRequest", "POST", "https://localhost", strings.NewReader("api_key=api-key&format=json")).Return(res, nil)
 			testutil.On("makeRequest", res).Return(&http.Response{Body: io.NopCloser(strings.NewReader("{}")), StatusCode: http.StatusOK}, nil)
@@ -92,12 +107,73 @@ func TestInitiatePostRequest(t *testing.T) {
 		}, verifyMocks: func() {
 			testutil.AssertExpectations(t)
 		}, want: map[string]interface{}{}, wantErr: false},
+		{name: "should send the detail flags of getMonitors as is", args: args{dataMap: map[string]interface{}{CustomHttpHeadersField: 1, CustomHttpStatusesField: 1}, endpoint: GetMonitorsEndpoint}, setupMocks: func() {
+			testutil = &testHttpUtil{}
+			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
+			testutil.On("LookUpEnv", UptimeRobotApiUrlEnv).Return("https://localhost/", true)
+			testutil.On("LookUpEnv", mock.Anything).Return("", false)
+			res := httptest.NewRequest("POST", "https://localhost/"+GetMonitorsEndpoint, nil)
+			testutil.On("newRequest", "POST", "https://localhost/"+GetMonitorsEndpoint, strings.NewReader("api_key=api-key&custom_http_headers=1&custom_http_statuses=1&format=json")).Return(res, nil)
+			testutil.On("makeRequest", res).Return(&http.Response{Body: io.NopCloser(strings.NewReader("{}")), StatusCode: http.StatusOK}, nil)
+			httputil.IHttpUtil = testutil
+
+		}, verifyMocks: func() {
+			testutil.AssertExpectations(t)
+		}, want: map[string]interface{}{}, wantErr: false},
+		{name: "should retry a rate limited get request after Retry-After", args: args{dataMap: map[string]interface{}{}, endpoint: GetMonitorsEndpoint}, setupMocks: func() {
+			testutil = &testHttpUtil{}
+			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
+			testutil.On("LookUpEnv", UptimeRobotApiUrlEnv).Return("https://localhost/", true)
+			testutil.On("LookUpEnv", UptimeRobotMaxAttemptsEnv).Return("3", true)
+			testutil.On("LookUpEnv", mock.Anything).Return("", false)
+			res := httptest.NewRequest("POST", "https://localhost/"+GetMonitorsEndpoint, nil)
+			testutil.On("newRequest", "POST", "https://localhost/"+GetMonitorsEndpoint, strings.NewReader("api_key=api-key&format=json")).Return(res, nil)
+			testutil.On("makeRequest", res).Return(&http.Response{Body: io.NopCloser(strings.NewReader("rate limited")), StatusCode: http.StatusTooManyRequests, Header: http.Header{RetryAfterHeader: []string{"2"}}}, nil).Once()
+			testutil.On("makeRequest", res).Return(&http.Response{Body: io.NopCloser(strings.NewReader("{}")), StatusCode: http.StatusOK}, nil).Once()
+			testutil.On("sleep", 2*time.Second).Return()
+			httputil.IHttpUtil = testutil
+
+		}, verifyMocks: func() {
+			testutil.AssertExpectations(t)
+			testutil.AssertNumberOfCalls(t, "makeRequest", 2)
+		}, want: map[string]interface{}{}, wantErr: false},
+		{name: "should give up after the max number of attempts", args: args{dataMap: map[string]interface{}{}, endpoint: EditMonitorEndpoint}, setupMocks: func() {
+			testutil = &testHttpUtil{}
+			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
+			testutil.On("LookUpEnv", UptimeRobotApiUrlEnv).Return("https://localhost/", true)
+			testutil.On("LookUpEnv", UptimeRobotMaxAttemptsEnv).Return("2", true)
+			testutil.On("LookUpEnv", mock.Anything).Return("", false)
+			res := httptest.NewRequest("POST", "https://localhost/"+EditMonitorEndpoint, nil)
+			testutil.On("newRequest", "POST", "https://localhost/"+EditMonitorEndpoint, strings.NewReader("api_key=api-key&format=json")).Return(res, nil)
+			testutil.On("makeRequest", res).Return(&http.Response{Body: io.NopCloser(strings.NewReader("unavailable")), StatusCode: http.StatusServiceUnavailable}, nil).Once()
+			testutil.On("makeRequest", res).Return(&http.Response{Body: io.NopCloser(strings.NewReader("unavailable")), StatusCode: http.StatusServiceUnavailable}, nil).Once()
+			testutil.On("sleep", mock.Anything).Return().Once()
+			httputil.IHttpUtil = testutil
+
+		}, verifyMocks: func() {
+			testutil.AssertExpectations(t)
+			testutil.AssertNumberOfCalls(t, "makeRequest", 2)
+		}, want: nil, wantErr: true},
+		{name: "should never retry creating a monitor", args: args{dataMap: map[string]interface{}{}, endpoint: NewMonitorEndpoint}, setupMocks: func() {
+			testutil = &testHttpUtil{}
+			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
+			testutil.On("LookUpEnv", UptimeRobotApiUrlEnv).Return("https://localhost/", true)
+			testutil.On("LookUpEnv", UptimeRobotRequestTimeoutEnv).Return("", false)
+			res := httptest.NewRequest("POST", "https://localhost/"+NewMonitorEndpoint, nil)
+			testutil.On("newRequest", "POST", "https://localhost/"+NewMonitorEndpoint, strings.NewReader("api_key=api-key&format=json")).Return(res, nil)
+			testutil.On("makeRequest", res).Return(&http.Response{Body: io.NopCloser(strings.NewReader("unavailable")), StatusCode: http.StatusServiceUnavailable}, nil).Once()
+			httputil.IHttpUtil = testutil
+
+		}, verifyMocks: func() {
+			testutil.AssertExpectations(t)
+			testutil.AssertNotCalled(t, "sleep", mock.Anything)
+		}, want: nil, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupMocks()
 			defer tt.verifyMocks()
-			got, err := httputil.InitiatePostRequest(tt.args.endpoint, tt.args.dataMap)
+			got, err := httputil.InitiatePostRequest(context.Background(), tt.args.endpoint, tt.args.dataMap)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("InitiatePostRequest() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -130,6 +206,51 @@ func TestValidateUrl(t *testing.T) {
 	}
 }
 
+func TestFormValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "should keep strings", value: "api", want: "api"},
+		{name: "should format floats without an exponent", value: float64(1000000), want: "1000000"},
+		{name: "should format enums as their number", value: model.MonitorTypeKeyword, want: "2"},
+		{name: "should format ids as numbers", value: model.ID(777712827), want: "777712827"},
+		{name: "should format maps as JSON", value: map[string]interface{}{"Accept": "text/html"}, want: `{"Accept":"text/html"}`},
+		{name: "should format nil as empty", value: nil, want: ""},
+		{name: "should format other values as printed", value: uint8(1), want: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormValue(tt.value); got != tt.want {
+				t.Errorf("FormValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formMap(t *testing.T) {
+	caseInsensitive := model.KeywordCaseInsensitive
+	got, err := formMap(model.MonitorConfig{
+		FriendlyName:    "api",
+		Type:            model.MonitorTypeKeyword,
+		KeywordCaseType: &caseInsensitive,
+		Interval:        300,
+	})
+	if err != nil {
+		t.Fatalf("formMap() error = %v", err)
+	}
+	want := map[string]interface{}{
+		FriendlyNameField:    "api",
+		TypeField:            model.MonitorTypeKeyword,
+		KeywordCaseTypeField: model.KeywordCaseInsensitive,
+		IntervalField:        float64(300),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("formMap() = %v, want %v", got, want)
+	}
+}
+
 func Test_cleanPayload(t *testing.T) {
 	type args struct {
 		dataMap map[string]interface{}
@@ -140,12 +261,19 @@ func Test_cleanPayload(t *testing.T) {
 		want map[string]interface{}
 	}{
 		{name: "should remove all unwanted fields", args: args{dataMap: map[string]interface{}{
-			FormatKeyField:       "xml",
-			ApiKeyField:          "api-key",
-			HttpMethodField:      "POST",
-			PostValueField:       "test",
-			PostContentTypeField: "application/xml",
+			FormatKeyField: "xml",
+			ApiKeyField:    "api-key",
 		}}, want: map[string]interface{}{}},
+		{name: "should keep the request body fields of monitors", args: args{dataMap: map[string]interface{}{
+			ApiKeyField:          "api-key",
+			HttpMethodField:      uint8(3),
+			PostValueField:       `{"check":"deep"}`,
+			PostContentTypeField: uint8(1),
+		}}, want: map[string]interface{}{
+			HttpMethodField:      uint8(3),
+			PostValueField:       `{"check":"deep"}`,
+			PostContentTypeField: uint8(1),
+		}},
 	}
 	for _, tt := range tests {
 
@@ -158,3 +286,170 @@ func Test_cleanPayload(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &retryPolicy{maxAttempts: 5, baseDelay: time.Second, maxDelay: 10 * time.Second}
+	tests := []struct {
+		name    string
+		attempt int
+		header  http.Header
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "should back off exponentially with jitter", attempt: 3, min: 2 * time.Second, max: 4 * time.Second},
+		{name: "should cap the backoff at the max delay", attempt: 10, min: 5 * time.Second, max: 10 * time.Second},
+		{name: "should wait for Retry-After seconds", attempt: 1, header: http.Header{RetryAfterHeader: []string{"7"}}, min: 7 * time.Second, max: 7 * time.Second},
+		{name: "should cap Retry-After at the max delay", attempt: 1, header: http.Header{RetryAfterHeader: []string{"120"}}, min: 10 * time.Second, max: 10 * time.Second},
+		{name: "should wait for the rate limit reset once exhausted", attempt: 1, header: http.Header{RateLimitRemainingHeader: []string{"0"}, RateLimitResetHeader: []string{"4"}}, min: 4 * time.Second, max: 4 * time.Second},
+		{name: "should ignore the rate limit reset while requests remain", attempt: 1, header: http.Header{RateLimitRemainingHeader: []string{"3"}, RateLimitResetHeader: []string{"4"}}, min: 500 * time.Millisecond, max: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// set the headers the way they are received, canonicalised
+			header := http.Header{}
+			for key, values := range tt.header {
+				header.Set(key, values[0])
+			}
+			if got := policy.delay(tt.attempt, header); got < tt.min || got > tt.max {
+				t.Errorf("delay() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestIsRetryableEndpoint(t *testing.T) {
+	for endpoint, want := range map[string]bool{GetMonitorsEndpoint: true, EditMonitorEndpoint: true, NewMonitorEndpoint: false, DeleteMonitorEndpoint: false} {
+		if got := IsRetryableEndpoint(endpoint); got != want {
+			t.Errorf("IsRetryableEndpoint(%s) = %v, want %v", endpoint, got, want)
+		}
+	}
+}
+
+func TestInitiatePostRequestCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	testutil := &testHttpUtil{}
+	testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
+	testutil.On("LookUpEnv", UptimeRobotApiUrlEnv).Return(server.URL+"/", true)
+	testutil.On("LookUpEnv", UptimeRobotRequestTimeoutEnv).Return("50ms", true)
+	testutil.On("LookUpEnv", UptimeRobotMaxAttemptsEnv).Return("1", true)
+	testutil.On("LookUpEnv", mock.Anything).Return("", false)
+	httputil := &HttpUtil{IHttpUtil: &hangingHttpUtil{testHttpUtil: testutil}}
+
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{name: "should time out a hung request", ctx: func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}, wantErr: context.DeadlineExceeded},
+		{name: "should stop when the context is cancelled", ctx: func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx, cancel
+		}, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			if _, err := httputil.InitiatePostRequest(ctx, GetMonitorsEndpoint, map[string]interface{}{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("InitiatePostRequest() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// hangingHttpUtil sends requests for real so that timeouts and cancellation reach the client.
+type hangingHttpUtil struct {
+	*testHttpUtil
+}
+
+func (h *hangingHttpUtil) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+func (h *hangingHttpUtil) makeRequest(req *http.Request) (*http.Response, error) {
+	return http.DefaultClient.Do(req)
+}
+
+func TestInitiatePostRequestCancelledAfterResponse(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	testutil := &testHttpUtil{}
+	testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
+	testutil.On("LookUpEnv", UptimeRobotApiUrlEnv).Return("https://localhost/", true)
+	testutil.On("LookUpEnv", mock.Anything).Return("", false)
+	req := httptest.NewRequest("POST", "https://localhost/"+NewMonitorEndpoint, nil)
+	testutil.On("newRequest", "POST", "https://localhost/"+NewMonitorEndpoint, mock.Anything).Return(req, nil)
+	// the monitor is created and the run is cancelled before the response is handled
+	testutil.On("makeRequest", req).Run(func(mock.Arguments) { cancel() }).Return(&http.Response{
+		Body: io.NopCloser(strings.NewReader(`{"stat":"ok","monitor":{"id":1}}`)), StatusCode: http.StatusOK,
+	}, nil)
+	httputil := &HttpUtil{IHttpUtil: testutil}
+
+	got, err := httputil.InitiatePostRequest(ctx, NewMonitorEndpoint, map[string]interface{}{})
+	want := map[string]interface{}{"stat": "ok", "monitor": map[string]interface{}{"id": float64(1)}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("InitiatePostRequest() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestApiKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "api.key")
+	if err := os.WriteFile(keyFile, []byte("file-api-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	missingFile := filepath.Join(t.TempDir(), "missing.key")
+
+	tests := []struct {
+		name       string
+		setupMocks func(testutil *testHttpUtil)
+		want       string
+		wantErr    bool
+		errPrefix  string
+	}{
+		{name: "should prefer the API key over the key file", setupMocks: func(testutil *testHttpUtil) {
+			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("api-key", true)
+		}, want: "api-key"},
+		{name: "should read the key file trimmed", setupMocks: func(testutil *testHttpUtil) {
+			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("", false)
+			testutil.On("LookUpEnv", UptimeRobotApiKeyFileEnv).Return(keyFile, true)
+		}, want: "file-api-key"},
+		{name: "should fail if the key file cannot be read", setupMocks: func(testutil *testHttpUtil) {
+			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("", false)
+			testutil.On("LookUpEnv", UptimeRobotApiKeyFileEnv).Return(missingFile, true)
+		}, wantErr: true, errPrefix: "API key could not be read from " + missingFile + ":"},
+		{name: "should fail if neither is set", setupMocks: func(testutil *testHttpUtil) {
+			testutil.On("LookUpEnv", UptimeRobotApiKeyEnv).Return("", false)
+			testutil.On("LookUpEnv", UptimeRobotApiKeyFileEnv).Return("", false)
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil := &testHttpUtil{}
+			tt.setupMocks(testutil)
+			defer testutil.AssertExpectations(t)
+
+			got, err := ApiKey(testutil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ApiKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.errPrefix != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.errPrefix)) {
+				t.Errorf("ApiKey() error = %v, want it to start with %s", err, tt.errPrefix)
+			}
+			if got != tt.want {
+				t.Errorf("ApiKey() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
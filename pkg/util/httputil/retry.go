@@ -0,0 +1,129 @@
+package httputil
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	UptimeRobotMaxAttemptsEnv    = "UPTIME_ROBOT_MAX_ATTEMPTS"
+	UptimeRobotRetryBaseDelayEnv = "UPTIME_ROBOT_RETRY_BASE_DELAY"
+	UptimeRobotRetryMaxDelayEnv  = "UPTIME_ROBOT_RETRY_MAX_DELAY"
+)
+
+const (
+	DefaultMaxAttempts    = 5
+	DefaultRetryBaseDelay = time.Second
+	DefaultRetryMaxDelay  = time.Minute
+)
+
+const (
+	RetryAfterHeader         = "Retry-After"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+const MsgRetryingRequest = "%s request failed with '%v', retrying in %s (attempt %d of %d)"
+
+// retryPolicy is how failed requests to retryable endpoints are retried.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+/*
+*
+Returns whether a request to endpoint can be sent again after a failure i.e. it only reads (get*) or is idempotent
+(edit*). Creating and deleting are never retried as the first request may have been applied.
+*/
+func IsRetryableEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "get") || strings.HasPrefix(endpoint, "edit")
+}
+
+/*
+*
+Reads the retry policy from UPTIME_ROBOT_MAX_ATTEMPTS (1 disables retries), UPTIME_ROBOT_RETRY_BASE_DELAY and
+UPTIME_ROBOT_RETRY_MAX_DELAY (durations e.g. 500ms, 1m).
+*/
+func (util *HttpUtil) retryPolicy() (*retryPolicy, error) {
+	policy := &retryPolicy{maxAttempts: DefaultMaxAttempts, baseDelay: DefaultRetryBaseDelay, maxDelay: DefaultRetryMaxDelay}
+	if val, found := util.IHttpUtil.LookUpEnv(UptimeRobotMaxAttemptsEnv); found {
+		maxAttempts, err := strconv.Atoi(val)
+		if err != nil || maxAttempts < 1 {
+			return nil, fmt.Errorf("invalid %s: %s needs to be a positive number", UptimeRobotMaxAttemptsEnv, val)
+		}
+		policy.maxAttempts = maxAttempts
+	}
+	for env, delay := range map[string]*time.Duration{UptimeRobotRetryBaseDelayEnv: &policy.baseDelay, UptimeRobotRetryMaxDelayEnv: &policy.maxDelay} {
+		if val, found := util.IHttpUtil.LookUpEnv(env); found {
+			duration, err := time.ParseDuration(val)
+			if err != nil || duration < 0 {
+				return nil, fmt.Errorf("invalid %s: %s needs to be a duration e.g. 1s", env, val)
+			}
+			*delay = duration
+		}
+	}
+	return policy, nil
+}
+
+/*
+*
+Returns how long to wait before the attempt following attempt. The wait asked for by the API through Retry-After, or
+X-RateLimit-Reset once X-RateLimit-Remaining is 0, is used when present otherwise the base delay is doubled on every
+attempt with jitter. The wait never exceeds the max delay.
+*/
+func (policy *retryPolicy) delay(attempt int, header http.Header) time.Duration {
+	if delay, ok := rateLimitDelay(header); ok {
+		if delay > policy.maxDelay {
+			return policy.maxDelay
+		}
+		return delay
+	}
+
+	delay := policy.baseDelay
+	for i := 1; i < attempt && delay < policy.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > policy.maxDelay {
+		delay = policy.maxDelay
+	}
+	// equal jitter: half of the delay is kept, the other half is random
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int63n(half+1))
+	}
+	return delay
+}
+
+func rateLimitDelay(header http.Header) (time.Duration, bool) {
+	if retryAfter := header.Get(RetryAfterHeader); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return nonNegative(time.Until(date)), true
+		}
+	}
+
+	if header.Get(RateLimitRemainingHeader) == "0" {
+		if reset, err := strconv.ParseInt(header.Get(RateLimitResetHeader), 10, 64); err == nil {
+			// the reset is either a unix timestamp or a number of seconds
+			if reset > 1e9 {
+				return nonNegative(time.Until(time.Unix(reset, 0))), true
+			}
+			return time.Duration(reset) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+func nonNegative(duration time.Duration) time.Duration {
+	if duration < 0 {
+		return 0
+	}
+	return duration
+}
@@ -0,0 +1,123 @@
+package planutil
+
+import (
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"strings"
+)
+
+const (
+	TextFormat     = "text"
+	MarkdownFormat = "markdown"
+)
+
+const MsgFormatNotSupported = "plan format %s is not supported"
+
+// Order in which actions are counted on the summary line.
+var actions = []string{model.Created, model.Updated, model.Recreated, model.Deleted}
+
+// Returns an error unless format is supported by Render, so that it can be checked before the run.
+func ValidateFormat(format string) error {
+	switch strings.ToLower(format) {
+	case TextFormat, MarkdownFormat, "":
+		return nil
+	default:
+		return fmt.Errorf(MsgFormatNotSupported, format)
+	}
+}
+
+/*
+*
+Renders the changes of a dry run on resource as text or as a Markdown table that can be pasted into a PR comment.
+*/
+func Render(resource string, changes []model.Change, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case TextFormat, "":
+		return renderText(resource, changes), nil
+	case MarkdownFormat:
+		return renderMarkdown(resource, changes), nil
+	default:
+		return "", fmt.Errorf(MsgFormatNotSupported, format)
+	}
+}
+
+func renderText(resource string, changes []model.Change) string {
+	var builder strings.Builder
+	for _, change := range changes {
+		builder.WriteString(fmt.Sprintf("%s %s will be %s%s%s\n", resource, change.Name, change.Action, formatId(change.Id), formatNote(change.Note)))
+		if change.Action != model.Deleted && change.Action != model.Unchanged && len(change.Fields) == 0 {
+			builder.WriteString("  (no changes)\n")
+		}
+		for _, field := range change.Fields {
+			builder.WriteString(fmt.Sprintf("  %s: %q -> %q\n", field.Field, field.Remote, field.Desired))
+		}
+	}
+	builder.WriteString(fmt.Sprintf("Plan: %s\n", summary(changes)))
+	return builder.String()
+}
+
+func renderMarkdown(resource string, changes []model.Change) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("### %s plan: %s\n\n", resource, summary(changes)))
+	if len(changes) == 0 {
+		return builder.String()
+	}
+
+	builder.WriteString("| Action | Name | Field | Remote | Desired |\n")
+	builder.WriteString("|--------|------|-------|--------|---------|\n")
+	for _, change := range changes {
+		name := escapeMarkdown(change.Name) + formatId(change.Id)
+		if len(change.Fields) == 0 {
+			builder.WriteString(fmt.Sprintf("| %s | %s | | | |\n", change.Action+escapeMarkdown(formatNote(change.Note)), name))
+		}
+		for idx, field := range change.Fields {
+			action := change.Action + escapeMarkdown(formatNote(change.Note))
+			if idx > 0 {
+				action, name = "", ""
+			}
+			builder.WriteString(fmt.Sprintf("| %s | %s | `%s` | %s | %s |\n", action, name, field.Field, formatMarkdownValue(field.Remote), formatMarkdownValue(field.Desired)))
+		}
+	}
+	return builder.String()
+}
+
+// Returns e.g. "1 created, 2 updated, 0 recreated, 0 deleted.", unchanged resources are only counted when there are any.
+func summary(changes []model.Change) string {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Action]++
+	}
+	parts := make([]string, len(actions))
+	for idx, action := range actions {
+		parts[idx] = fmt.Sprintf("%d %s", counts[action], action)
+	}
+	if counts[model.Unchanged] > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[model.Unchanged], model.Unchanged))
+	}
+	return strings.Join(parts, ", ") + "."
+}
+
+func formatId(id string) string {
+	if id == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (id %s)", id)
+}
+
+func formatNote(note string) string {
+	if note == "" {
+		return ""
+	}
+	return fmt.Sprintf(", %s", note)
+}
+
+func formatMarkdownValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(escapeMarkdown(value), "`", "'") + "`"
+}
+
+func escapeMarkdown(value string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(value)
+}
@@ -0,0 +1,85 @@
+package planutil
+
+import (
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"testing"
+)
+
+var changes = []model.Change{
+	{Action: model.Updated, Id: "1", Name: "api", Fields: []model.FieldChange{
+		{Field: "interval", Remote: "300", Desired: "60"},
+		{Field: "url", Remote: "https://api.localhost", Desired: "https://api.localhost/a|b"},
+	}},
+	{Action: model.Created, Name: "web", Fields: []model.FieldChange{{Field: "friendly_name", Desired: "web"}}},
+	{Action: model.Recreated, Id: "2", Name: "db", Fields: []model.FieldChange{{Field: "type", Remote: "1", Desired: "4"}}, Note: "requires -allow-recreate"},
+	{Action: model.Deleted, Id: "3", Name: "old"},
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		changes []model.Change
+		want    string
+		wantErr bool
+	}{
+		{name: "should render text", format: TextFormat, changes: changes, want: `monitor api will be updated (id 1)
+  interval: "300" -> "60"
+  url: "https://api.localhost" -> "https://api.localhost/a|b"
+monitor web will be created
+  friendly_name: "" -> "web"
+monitor db will be recreated (id 2), requires -allow-recreate
+  type: "1" -> "4"
+monitor old will be deleted (id 3)
+Plan: 1 created, 1 updated, 1 recreated, 1 deleted.
+`},
+		{name: "should render no changes as text", format: "", changes: []model.Change{{Action: model.Updated, Id: "1", Name: "api"}}, want: `monitor api will be updated (id 1)
+  (no changes)
+Plan: 0 created, 1 updated, 0 recreated, 0 deleted.
+`},
+		{name: "should count unchanged resources", format: TextFormat, changes: []model.Change{{Action: model.Unchanged, Id: "1", Name: "api"}}, want: `monitor api will be unchanged (id 1)
+Plan: 0 created, 0 updated, 0 recreated, 0 deleted, 1 unchanged.
+`},
+		{name: "should render markdown", format: "Markdown", changes: changes, want: "### monitor plan: 1 created, 1 updated, 1 recreated, 1 deleted.\n\n" +
+			"| Action | Name | Field | Remote | Desired |\n" +
+			"|--------|------|-------|--------|---------|\n" +
+			"| updated | api (id 1) | `interval` | `300` | `60` |\n" +
+			"|  |  | `url` | `https://api.localhost` | `https://api.localhost/a\\|b` |\n" +
+			"| created | web | `friendly_name` |  | `web` |\n" +
+			"| recreated, requires -allow-recreate | db (id 2) | `type` | `1` | `4` |\n" +
+			"| deleted | old (id 3) | | | |\n"},
+		{name: "should render empty markdown plan", format: MarkdownFormat, want: "### monitor plan: 0 created, 0 updated, 0 recreated, 0 deleted.\n\n"},
+		{name: "should fail on unsupported format", format: "html", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render("monitor", tt.changes, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Render() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Render() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		wantErr bool
+	}{
+		{name: "should accept markdown in any case", format: "Markdown"},
+		{name: "should accept the default format", format: ""},
+		{name: "should reject unknown formats", format: "html", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFormat(tt.format); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package redactutil
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/onaio/uptimerobot-tooling/pkg/provider"
+	log "github.com/sirupsen/logrus"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UptimeRobotRedactFieldsEnv is a comma separated list of fields redacted on top of DefaultFields.
+const UptimeRobotRedactFieldsEnv = "UPTIME_ROBOT_REDACT_FIELDS"
+
+// Redacted replaces secrets in logs, errors, plans and the journal.
+const Redacted = "[REDACTED]"
+
+/*
+*
+Secrets shorter than this are only redacted from the fields holding them and not from free text such as log messages,
+since replacing every occurrence of e.g. a two letter password would garble the logs.
+*/
+const minSecretLength = 4
+
+// DefaultFields are the fields whose values are secrets.
+var DefaultFields = []string{"api_key", "http_password", "http_username"}
+
+/*
+*
+Fields whose values are also redacted from free text once collected, see Collect. The values of the other secret fields
+may be common words e.g. a username admin and are only redacted from the fields holding them.
+*/
+var collectedFields = []string{"api_key", "http_password"}
+
+// Field holding the custom HTTP headers of a monitor, the values of the headers in it named as credentials are secrets.
+const customHttpHeadersField = "custom_http_headers"
+
+// Parts of header names whose values are credentials e.g. Authorization, Proxy-Authorization, X-Auth-Token, X-Api-Key.
+var secretHeaderParts = []string{"auth", "token", "key", "secret", "cookie", "session"}
+
+// Secret values seen so far, see Collect. They are kept longest first so that a secret containing another is replaced whole.
+var secrets = struct {
+	sync.RWMutex
+	values []string
+	seen   map[string]bool
+}{seen: make(map[string]bool)}
+
+// Returns whether the values of field are secrets, see DefaultFields and UPTIME_ROBOT_REDACT_FIELDS.
+func IsSecretField(field string) bool {
+	for _, secretField := range Fields() {
+		if strings.EqualFold(field, secretField) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns DefaultFields and the fields in UPTIME_ROBOT_REDACT_FIELDS.
+func Fields() []string {
+	fields := append([]string{}, DefaultFields...)
+	extraFields, _ := provider.LookUpEnv(UptimeRobotRedactFieldsEnv)
+	for _, field := range strings.Split(extraFields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Returns whether the value of the HTTP header name is a credential.
+func IsSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range secretHeaderParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return IsSecretField(name)
+}
+
+/*
+*
+AddSecret makes String redact value from now on, along with its form and JSON encodings since API errors may echo the
+request.
+*/
+func AddSecret(value string) {
+	if len(value) < minSecretLength {
+		return
+	}
+	secrets.Lock()
+	defer secrets.Unlock()
+	if secrets.seen[value] {
+		return
+	}
+	secrets.seen[value] = true
+	secrets.values = append(secrets.values, value)
+	encodings := []string{url.QueryEscape(value)}
+	if encoded, err := json.Marshal(value); err == nil {
+		encodings = append(encodings, strings.Trim(string(encoded), `"`))
+	}
+	for _, encoding := range encodings {
+		if !secrets.seen[encoding] {
+			secrets.seen[encoding] = true
+			secrets.values = append(secrets.values, encoding)
+		}
+	}
+	sort.SliceStable(secrets.values, func(i, j int) bool {
+		return len(secrets.values[i]) > len(secrets.values[j])
+	})
+}
+
+// Collect adds the values of the api_key and http_password fields and of the credential headers in custom_http_headers of
+// value, in nested objects and lists too, so that String redacts them from free text.
+func Collect(value interface{}) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for field, fieldValue := range typed {
+			collectField(field, fieldValue)
+		}
+	case []map[string]interface{}:
+		for _, item := range typed {
+			Collect(item)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			Collect(item)
+		}
+	}
+}
+
+func collectField(field string, value interface{}) {
+	if field == customHttpHeadersField {
+		collectHeaders(value)
+		return
+	}
+	for _, collectedField := range collectedFields {
+		if strings.EqualFold(field, collectedField) {
+			if secret, ok := value.(string); ok {
+				AddSecret(secret)
+			}
+			return
+		}
+	}
+	Collect(value)
+}
+
+// Adds the values of the credential headers of headers, an object or its JSON encoding, see IsSecretHeader.
+func collectHeaders(headers interface{}) {
+	if encoded, ok := headers.(string); ok {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+			return
+		}
+		headers = decoded
+	}
+	headerMap, ok := headers.(map[string]interface{})
+	if !ok {
+		Collect(headers)
+		return
+	}
+	for name, value := range headerMap {
+		if secret, ok := value.(string); ok && IsSecretHeader(name) {
+			AddSecret(secret)
+		}
+	}
+}
+
+// Returns s with every secret added so far replaced with Redacted.
+func String(s string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+	for _, secret := range secrets.values {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	return s
+}
+
+// Returns err with the secrets in its message redacted, err itself when it holds none.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	if message := String(err.Error()); message != err.Error() {
+		return errors.New(message)
+	}
+	return err
+}
+
+// Returns a copy of data with its secrets redacted, see Value.
+func Map(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	return Value(data).(map[string]interface{})
+}
+
+/*
+*
+Returns a copy of value with the values of the secret fields (see IsSecretField) and of the credential headers in
+custom_http_headers (see IsSecretHeader) replaced with Redacted, in nested objects and lists too. Its api_key,
+http_password and credential headers are collected (see Collect) so that they are redacted from free text as well, the
+other strings of value included.
+*/
+func Value(value interface{}) interface{} {
+	// the secrets of value are collected first so that they are redacted from its other strings too
+	Collect(value)
+	return redactValue(value)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(typed))
+		for field, fieldValue := range typed {
+			redacted[field] = redactField(field, fieldValue)
+		}
+		return redacted
+	case []map[string]interface{}:
+		redacted := make([]map[string]interface{}, len(typed))
+		for idx, item := range typed {
+			redacted[idx] = redactValue(item).(map[string]interface{})
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(typed))
+		for idx, item := range typed {
+			redacted[idx] = redactValue(item)
+		}
+		return redacted
+	case string:
+		return String(typed)
+	case error:
+		return Error(typed)
+	default:
+		return value
+	}
+}
+
+// Returns value, the value of field, redacted as it would be in an object, see Value.
+func Field(field string, value interface{}) interface{} {
+	collectField(field, value)
+	return redactField(field, value)
+}
+
+func redactField(field string, value interface{}) interface{} {
+	switch {
+	case IsSecretField(field):
+		return redactSecret(value)
+	case field == customHttpHeadersField:
+		return redactHeaders(value)
+	default:
+		return redactValue(value)
+	}
+}
+
+// Returns Redacted, empty values are kept since they tell that the field is not set.
+func redactSecret(value interface{}) interface{} {
+	if value == nil || value == "" {
+		return value
+	}
+	return Redacted
+}
+
+// Redacts the credential headers of headers, an object or its JSON encoding.
+func redactHeaders(headers interface{}) interface{} {
+	if encoded, ok := headers.(string); ok {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+			return String(encoded)
+		}
+		reEncoded, err := json.Marshal(redactHeaders(decoded))
+		if err != nil {
+			return Redacted
+		}
+		return string(reEncoded)
+	}
+	headerMap, ok := headers.(map[string]interface{})
+	if !ok {
+		return redactValue(headers)
+	}
+	redacted := make(map[string]interface{}, len(headerMap))
+	for name, value := range headerMap {
+		if IsSecretHeader(name) {
+			redacted[name] = redactSecret(value)
+		} else {
+			redacted[name] = redactValue(value)
+		}
+	}
+	return redacted
+}
+
+/*
+*
+Formatter redacts the secrets added so far (see AddSecret) and the secret fields from the message and fields of log
+entries before they are formatted by the wrapped Formatter.
+*/
+type Formatter struct {
+	log.Formatter
+}
+
+func (formatter *Formatter) Format(entry *log.Entry) ([]byte, error) {
+	redacted := *entry
+	redacted.Message = String(entry.Message)
+	redacted.Data = make(log.Fields, len(entry.Data))
+	for field, value := range entry.Data {
+		redacted.Data[field] = Field(field, value)
+	}
+	return formatter.Formatter.Format(&redacted)
+}
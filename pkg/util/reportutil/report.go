@@ -0,0 +1,228 @@
+package reportutil
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	JsonFormat  = "json"
+	JUnitFormat = "junit"
+)
+
+// Exit codes of a run.
+const (
+	ExitSuccess        = 0
+	ExitPartialFailure = 1
+	ExitTotalFailure   = 2
+	ExitInputError     = 3
+	// ExitDriftDetected is returned by a successful dry run that would change monitors.
+	ExitDriftDetected = 4
+)
+
+// Status of an item on the report.
+const (
+	Succeeded = "succeeded"
+	Failed    = "failed"
+	Skipped   = "skipped"
+)
+
+const MsgReportFormatNotSupported = "report format %s is not supported"
+
+// Item is the outcome of a single resource of a run.
+type Item struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Action is what was done to the resource e.g. created, empty when it is not known or nothing was done.
+	Action string `json:"action,omitempty"`
+	Id     string `json:"id,omitempty"`
+	// ChangedFields are the fields an update changed.
+	ChangedFields []string `json:"changed_fields,omitempty"`
+	Source        string   `json:"source,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// Report is the outcome of a run that is written by -report.
+type Report struct {
+	Resource  string `json:"resource"`
+	Action    string `json:"action"`
+	ExitCode  int    `json:"exit_code"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	Skipped   int    `json:"skipped"`
+	Items     []Item `json:"items"`
+}
+
+/*
+*
+Builds the report of running action on resource from the results returned by HandleRequest. Skipped resources have no
+result to take their name from, so they are named after their position in the input e.g. #3.
+*/
+func NewReport(resource string, action string, results []map[string]interface{}, exitCode int) Report {
+	nameField := model.NameResultFields[strings.ToLower(resource)]
+	report := Report{Resource: strings.ToLower(resource), Action: action, ExitCode: exitCode, Items: make([]Item, 0, len(results))}
+	for idx, result := range results {
+		item := Item{Name: fmt.Sprintf("#%d", idx+1), Status: Succeeded}
+		if name := fmt.Sprint(result[nameField]); result[nameField] != nil && name != "" {
+			item.Name = name
+		}
+		if len(result) == 0 {
+			item.Status = Skipped
+			report.Skipped++
+			report.Items = append(report.Items, item)
+			continue
+		}
+		if result[model.ActionResultField] != nil {
+			item.Action = fmt.Sprint(result[model.ActionResultField])
+		}
+		if result[model.IdResultField] != nil {
+			item.Id = fmt.Sprint(result[model.IdResultField])
+		}
+		if changedFields, ok := result[model.ChangedFieldsResultField].([]string); ok {
+			item.ChangedFields = changedFields
+		}
+		if result[model.SourceResultField] != nil {
+			item.Source = fmt.Sprint(result[model.SourceResultField])
+		}
+		if result[model.ErrorResultField] != nil {
+			item.Status = Failed
+			item.Error = fmt.Sprint(result[model.ErrorResultField])
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report
+}
+
+/*
+*
+Returns the exit code of a run with summary: any failure or skipped resource is a partial failure unless nothing
+succeeded, in which case it is a total failure. drift is whether a dry run found changes.
+*/
+func ExitCode(summary Summary, drift bool) int {
+	if summary.Failed > 0 || summary.Skipped > 0 {
+		if summary.Succeeded == 0 {
+			return ExitTotalFailure
+		}
+		return ExitPartialFailure
+	}
+	if drift {
+		return ExitDriftDetected
+	}
+	return ExitSuccess
+}
+
+/*
+*
+Returns the format of a report written to path: JUnit XML for .xml files and JSON otherwise.
+*/
+func FormatOf(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		return JUnitFormat
+	}
+	return JsonFormat
+}
+
+// Returns an error unless format is supported by Write, so that it can be checked before the run.
+func ValidateFormat(format string) error {
+	switch strings.ToLower(format) {
+	case JsonFormat, JUnitFormat:
+		return nil
+	default:
+		return fmt.Errorf(MsgReportFormatNotSupported, format)
+	}
+}
+
+/*
+*
+Writes report to path as JSON or as JUnit XML where every resource is a test case.
+*/
+func (report Report) Write(path string, format string) error {
+	var output []byte
+	var err error
+	switch strings.ToLower(format) {
+	case JsonFormat:
+		output, err = json.MarshalIndent(report, "", "  ")
+	case JUnitFormat:
+		output, err = report.junit()
+	default:
+		return fmt.Errorf(MsgReportFormatNotSupported, format)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(output, '\n'), 0o644)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (report Report) junit() ([]byte, error) {
+	suite := junitTestSuite{
+		Name:     fmt.Sprintf("%s %s", report.Resource, report.Action),
+		Tests:    len(report.Items),
+		Failures: report.Failed,
+		Skipped:  report.Skipped,
+	}
+	for _, item := range report.Items {
+		testCase := junitTestCase{Name: item.Name, ClassName: report.Resource}
+		switch item.Status {
+		case Failed:
+			testCase.Failure = &junitMessage{Message: item.Error, Text: item.Error}
+		case Skipped:
+			testCase.Skipped = &junitMessage{Message: "not handled, the run stopped before it"}
+		}
+		var details []string
+		for _, detail := range [][2]string{{"action", item.Action}, {"id", item.Id}, {"source", item.Source}} {
+			if detail[1] != "" {
+				details = append(details, detail[0]+": "+detail[1])
+			}
+		}
+		testCase.SystemOut = strings.Join(details, "\n")
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	output, err := xml.MarshalIndent(junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), output...), nil
+}
@@ -0,0 +1,115 @@
+package reportutil
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var results = []map[string]interface{}{
+	{model.ErrorResultField: nil, model.MonitorNameResultField: "api", model.ActionResultField: model.Created, model.IdResultField: "777712827"},
+	{model.ErrorResultField: errors.New("url is invalid"), model.MonitorNameResultField: "web", model.SourceResultField: "monitors.yaml[1]"},
+	nil,
+}
+
+func TestNewReport(t *testing.T) {
+	want := Report{Resource: model.Monitor, Action: "update", ExitCode: ExitPartialFailure, Succeeded: 1, Failed: 1, Skipped: 1, Items: []Item{
+		{Name: "api", Status: Succeeded, Action: model.Created, Id: "777712827"},
+		{Name: "web", Status: Failed, Source: "monitors.yaml[1]", Error: "url is invalid"},
+		{Name: "#3", Status: Skipped},
+	}}
+	if got := NewReport("Monitor", "update", results, ExitPartialFailure); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewReport() = %v, want %v", got, want)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary Summary
+		drift   bool
+		want    int
+	}{
+		{name: "should succeed when everything succeeded", summary: Summary{Succeeded: 2}, want: ExitSuccess},
+		{name: "should succeed when there is nothing to do", summary: Summary{}, want: ExitSuccess},
+		{name: "should report drift on a successful dry run with changes", summary: Summary{Succeeded: 2}, drift: true, want: ExitDriftDetected},
+		{name: "should report a partial failure when some failed", summary: Summary{Succeeded: 1, Failed: 1}, drift: true, want: ExitPartialFailure},
+		{name: "should report a partial failure when some were skipped", summary: Summary{Succeeded: 1, Skipped: 1}, want: ExitPartialFailure},
+		{name: "should report a total failure when nothing succeeded", summary: Summary{Failed: 1, Skipped: 3}, want: ExitTotalFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.summary, tt.drift); got != tt.want {
+				t.Errorf("ExitCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	report := NewReport(model.Monitor, "update", results, ExitPartialFailure)
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{name: "should write json", file: "report.json", want: []string{`"status": "failed"`, `"error": "url is invalid"`, `"exit_code": 1`}},
+		{name: "should write junit xml with a test case per item", file: "report.xml", want: []string{
+			`<testsuite name="monitor update" tests="3" failures="1" skipped="1">`,
+			`<testcase name="api" classname="monitor">`,
+			`<failure message="url is invalid">url is invalid</failure>`,
+			`<skipped message=`,
+			`source: monitors.yaml[1]`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := report.Write(path, FormatOf(path)); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			output, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(output), want) {
+					t.Errorf("Write() wrote %s, want it to contain %s", output, want)
+				}
+			}
+			if FormatOf(path) == JsonFormat {
+				var got Report
+				if err := json.Unmarshal(output, &got); err != nil || !reflect.DeepEqual(got, report) {
+					t.Errorf("Write() wrote %v, want %v", got, report)
+				}
+			}
+		})
+	}
+
+	if err := report.Write(filepath.Join(t.TempDir(), "report"), "csv"); err == nil {
+		t.Errorf("Write() error = nil, want an error for an unsupported format")
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		wantErr bool
+	}{
+		{name: "should accept junit", format: JUnitFormat},
+		{name: "should reject unknown formats", format: "csv", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFormat(tt.format); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
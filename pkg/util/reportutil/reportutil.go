@@ -0,0 +1,73 @@
+package reportutil
+
+import (
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"strings"
+)
+
+// Failure is a resource whose request failed.
+type Failure struct {
+	Name   string
+	Source string
+	Error  string
+}
+
+// Summary counts the outcome of every resource of a run.
+type Summary struct {
+	Succeeded int
+	Failed    int
+	// Skipped resources were never handled since the run stopped at a failure or was cancelled.
+	Skipped  int
+	Failures []Failure
+}
+
+/*
+*
+Counts the results returned by HandleRequest: results with an error failed, nil or empty results were skipped and the
+rest succeeded. nameField is the result field holding the name of the resource (see model.NameResultFields).
+*/
+func Summarise(results []map[string]interface{}, nameField string) Summary {
+	var summary Summary
+	for _, result := range results {
+		if len(result) == 0 {
+			summary.Skipped++
+			continue
+		}
+		if result[model.ErrorResultField] == nil {
+			summary.Succeeded++
+			continue
+		}
+		summary.Failed++
+		failure := Failure{Error: fmt.Sprint(result[model.ErrorResultField])}
+		if result[nameField] != nil {
+			failure.Name = fmt.Sprint(result[nameField])
+		}
+		if result[model.SourceResultField] != nil {
+			failure.Source = fmt.Sprint(result[model.SourceResultField])
+		}
+		summary.Failures = append(summary.Failures, failure)
+	}
+	return summary
+}
+
+/*
+*
+Renders the counts on one line followed by a line per failure naming the resource of resourceType, where it was read
+from and why it failed.
+*/
+func (summary Summary) Render(resourceType string) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Summary: %d succeeded, %d failed, %d skipped\n", summary.Succeeded, summary.Failed, summary.Skipped))
+	for _, failure := range summary.Failures {
+		name := failure.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		if failure.Source != "" {
+			name = fmt.Sprintf("%s (%s)", name, failure.Source)
+		}
+		builder.WriteString(fmt.Sprintf("  %s %s: %s\n", resourceType, name, failure.Error))
+	}
+	return builder.String()
+}
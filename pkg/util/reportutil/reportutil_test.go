@@ -0,0 +1,34 @@
+package reportutil
+
+import (
+	"errors"
+	"github.com/onaio/uptimerobot-tooling/pkg/model"
+	"reflect"
+	"testing"
+)
+
+func TestSummarise(t *testing.T) {
+	results := []map[string]interface{}{
+		{model.ErrorResultField: nil, model.MonitorNameResultField: "api"},
+		{model.ErrorResultField: errors.New("url is invalid"), model.MonitorNameResultField: "web", model.SourceResultField: "monitors.yaml[1]"},
+		{model.ErrorResultField: errors.New("type needs to be specified"), model.MonitorNameResultField: ""},
+		nil,
+		{},
+	}
+	want := Summary{Succeeded: 1, Failed: 2, Skipped: 2, Failures: []Failure{
+		{Name: "web", Source: "monitors.yaml[1]", Error: "url is invalid"},
+		{Name: "", Error: "type needs to be specified"},
+	}}
+
+	got := Summarise(results, model.MonitorNameResultField)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarise() = %v, want %v", got, want)
+	}
+
+	wantRender := "Summary: 1 succeeded, 2 failed, 2 skipped\n" +
+		"  monitor web (monitors.yaml[1]): url is invalid\n" +
+		"  monitor (unnamed): type needs to be specified\n"
+	if gotRender := got.Render(model.Monitor); gotRender != wantRender {
+		t.Errorf("Render() = %q, want %q", gotRender, wantRender)
+	}
+}
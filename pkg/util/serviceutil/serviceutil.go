@@ -0,0 +1,173 @@
+package serviceutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/onaio/uptimerobot-tooling/pkg/service"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/redactutil"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	MsgUnknownErr       = "unknown error %s"
+	MsgListFetchErr     = "error occurred when fetching %s: %s was returned"
+	MsgListFieldInvalid = "%s field in %s response is invalid"
+)
+
+/*
+*
+Sends data to endpoint and converts an error object returned by the API into an error.
+*/
+func InitiateRequest(ctx context.Context, iService service.IService, endpoint string, data map[string]interface{}) (map[string]interface{}, error) {
+	// secrets sent are redacted from the logs and errors that follow e.g. an API error echoing the request
+	redactutil.Collect(data)
+	resultMap, err := iService.HttpInitiatePostRequest(ctx, endpoint, data)
+
+	if err != nil {
+		return nil, err
+	}
+	if resultMap != nil && resultMap[httputil.ErrorField] != nil {
+		returnError, ok := resultMap[httputil.ErrorField].(map[string]interface{})
+		if ok && returnError[httputil.MessageField] != nil {
+			return nil, errors.New(fmt.Sprint(returnError[httputil.MessageField]))
+		} else {
+			return nil, fmt.Errorf(MsgUnknownErr, redactutil.Map(resultMap))
+		}
+	} else {
+		return resultMap, nil
+	}
+}
+
+/*
+*
+Pages through a get* endpoint and returns every object found under listField.
+Pagination details are read either from the root of the response (getAlertContacts) or from its pagination object
+(getMonitors, getMWindows, getPSPs).
+*/
+func FetchAll(ctx context.Context, iService service.IService, endpoint string, listField string, requestBody map[string]interface{}) ([]map[string]interface{}, error) {
+	items := make([]map[string]interface{}, 0)
+	offset := 0
+	for {
+		data := make(map[string]interface{}, len(requestBody)+1)
+		for key, value := range requestBody {
+			data[key] = value
+		}
+		data[httputil.OffsetField] = offset
+
+		resultMap, err := InitiateRequest(ctx, iService, endpoint, data)
+		if err != nil {
+			return nil, err
+		}
+		if resultMap == nil || resultMap[listField] == nil {
+			return nil, fmt.Errorf(MsgListFetchErr, listField, redactutil.Map(resultMap))
+		}
+
+		list, ok := resultMap[listField].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf(MsgListFieldInvalid, listField, endpoint)
+		}
+		for _, item := range list {
+			if itemMap, ok := item.(map[string]interface{}); ok {
+				items = append(items, itemMap)
+			}
+		}
+
+		pagination := resultMap
+		if _pagination, ok := resultMap[httputil.PaginationField].(map[string]interface{}); ok {
+			pagination = _pagination
+		}
+		if pagination[httputil.TotalField] == nil || pagination[httputil.LimitField] == nil {
+			return items, nil
+		}
+		total, err := ToInt(pagination[httputil.TotalField])
+		if err != nil {
+			return nil, fmt.Errorf(MsgListFieldInvalid, httputil.TotalField, endpoint)
+		}
+		limit, err := ToInt(pagination[httputil.LimitField])
+		if err != nil {
+			return nil, fmt.Errorf(MsgListFieldInvalid, httputil.LimitField, endpoint)
+		}
+
+		offset += limit
+		if limit < 1 || len(list) == 0 || offset >= total {
+			return items, nil
+		}
+	}
+}
+
+/*
+*
+Converts a numeric value decoded from JSON (float64), an int or a numeric string into an int.
+*/
+func ToInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	default:
+		return strconv.Atoi(fmt.Sprint(v))
+	}
+}
+
+/*
+*
+Formats a value decoded from JSON as a string, integral float64 values (e.g. ids) are formatted without an exponent.
+*/
+func ToString(value interface{}) string {
+	if v, ok := value.(float64); ok && v == math.Trunc(v) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// Returns the ids of resources comma separated e.g. to list the candidates of an ambiguous friendly_name.
+func JoinIds(resources []map[string]interface{}) string {
+	ids := make([]string, len(resources))
+	for idx, resource := range resources {
+		ids[idx] = ToString(resource[httputil.IdField])
+	}
+	return strings.Join(ids, ", ")
+}
+
+/*
+*
+Runs work for the indexes 0 to count-1 on up to concurrency goroutines and waits for them to finish. Once work returns
+false or ctx is done no further indexes are started, with a concurrency of 1 this is the same as a loop that breaks on
+false. work is expected to store its result by index so that results keep the input order.
+*/
+func RunPool(ctx context.Context, concurrency int, count int, work func(idx int) bool) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var stopped int32
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				if atomic.LoadInt32(&stopped) == 1 || ctx.Err() != nil {
+					continue
+				}
+				if !work(idx) {
+					atomic.StoreInt32(&stopped, 1)
+				}
+			}
+		}()
+	}
+
+	for idx := 0; idx < count && atomic.LoadInt32(&stopped) == 0 && ctx.Err() == nil; idx++ {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+}
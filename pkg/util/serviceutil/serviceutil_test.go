@@ -0,0 +1,168 @@
+package serviceutil
+
+import (
+	"context"
+	"errors"
+	"github.com/onaio/uptimerobot-tooling/pkg/service"
+	"github.com/onaio/uptimerobot-tooling/pkg/util/httputil"
+	"github.com/stretchr/testify/mock"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type testService struct {
+	service.IService
+	mock.Mock
+}
+
+func (t *testService) HttpInitiatePostRequest(_ context.Context, endpoint string, dataMap map[string]interface{}) (map[string]interface{}, error) {
+	args := t.Called(endpoint, dataMap)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func TestInitiateRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		response map[string]interface{}
+		err      error
+		want     map[string]interface{}
+		wantErr  bool
+	}{
+		{name: "should return error when request returns error", response: nil, err: errors.New("error"), want: nil, wantErr: true},
+		{name: "should return result when request returns no error", response: map[string]interface{}{}, want: map[string]interface{}{}, wantErr: false},
+		{name: "should return error when response has error with message field", response: map[string]interface{}{
+			httputil.ErrorField: map[string]interface{}{httputil.MessageField: "message x"},
+		}, want: nil, wantErr: true},
+		{name: "should return error when response has error without message field", response: map[string]interface{}{
+			httputil.ErrorField: "x",
+		}, want: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testservice := &testService{}
+			testservice.On("HttpInitiatePostRequest", "endpoint", mock.IsType(map[string]interface{}{})).Return(tt.response, tt.err)
+			defer testservice.AssertExpectations(t)
+
+			got, err := InitiateRequest(context.Background(), testservice, "endpoint", map[string]interface{}{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("InitiateRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InitiateRequest() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchAll(t *testing.T) {
+	var testservice *testService
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		verifyMocks func()
+		want        []map[string]interface{}
+		wantErr     bool
+	}{
+		{name: "should page through root level pagination fields", setupMocks: func() {
+			testservice = &testService{}
+			testservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(map[string]interface{}{
+				"limit": float64(1), "total": float64(2),
+				httputil.AlertContactsField: []interface{}{map[string]interface{}{httputil.IdField: "1"}},
+			}, nil)
+			testservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, map[string]interface{}{httputil.OffsetField: 1}).Return(map[string]interface{}{
+				"limit": float64(1), "total": float64(2),
+				httputil.AlertContactsField: []interface{}{map[string]interface{}{httputil.IdField: "2"}},
+			}, nil)
+		}, verifyMocks: func() {
+			testservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{{httputil.IdField: "1"}, {httputil.IdField: "2"}}, wantErr: false},
+		{name: "should page through pagination object by limit", setupMocks: func() {
+			testservice = &testService{}
+			testservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, map[string]interface{}{httputil.OffsetField: 0}).Return(map[string]interface{}{
+				httputil.PaginationField:    map[string]interface{}{"limit": float64(2), "total": float64(3)},
+				httputil.AlertContactsField: []interface{}{map[string]interface{}{httputil.IdField: "1"}, map[string]interface{}{httputil.IdField: "2"}},
+			}, nil)
+			testservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, map[string]interface{}{httputil.OffsetField: 2}).Return(map[string]interface{}{
+				httputil.PaginationField:    map[string]interface{}{"limit": float64(2), "total": float64(3)},
+				httputil.AlertContactsField: []interface{}{map[string]interface{}{httputil.IdField: "3"}},
+			}, nil)
+		}, verifyMocks: func() {
+			testservice.AssertExpectations(t)
+		}, want: []map[string]interface{}{{httputil.IdField: "1"}, {httputil.IdField: "2"}, {httputil.IdField: "3"}}, wantErr: false},
+		{name: "should return error when list field is absent", setupMocks: func() {
+			testservice = &testService{}
+			testservice.On("HttpInitiatePostRequest", httputil.GetAlertContactsEndpoint, mock.IsType(map[string]interface{}{})).Return(map[string]interface{}{
+				"limit": float64(1), "total": float64(2),
+			}, nil)
+		}, verifyMocks: func() {
+			testservice.AssertExpectations(t)
+		}, want: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMocks()
+			defer tt.verifyMocks()
+			got, err := FetchAll(context.Background(), testservice, httputil.GetAlertContactsEndpoint, httputil.AlertContactsField, map[string]interface{}{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FetchAll() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FetchAll() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPool(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		failAt      int
+		cancelAt    int
+		want        []int
+	}{
+		{name: "should run every index once keeping results by index", concurrency: 4, failAt: -1, cancelAt: -1, want: []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+		{name: "should treat a concurrency below 1 as 1", concurrency: 0, failAt: -1, cancelAt: -1, want: []int{1, 1, 1}},
+		{name: "should stop at the first failure when sequential", concurrency: 1, failAt: 2, cancelAt: -1, want: []int{1, 1, 1, 0, 0}},
+		{name: "should stop once the context is cancelled", concurrency: 1, failAt: -1, cancelAt: 1, want: []int{1, 1, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]int, len(tt.want))
+			var mu sync.Mutex
+			running, maxRunning := 0, 0
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			RunPool(ctx, tt.concurrency, len(tt.want), func(idx int) bool {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+
+				got[idx]++
+				if idx == tt.cancelAt {
+					cancel()
+				}
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return idx != tt.failAt
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RunPool() ran %v, want %v", got, tt.want)
+			}
+			if maxRunning > tt.concurrency && maxRunning > 1 {
+				t.Errorf("RunPool() ran %d at the same time, want at most %d", maxRunning, tt.concurrency)
+			}
+		})
+	}
+}